@@ -42,6 +42,9 @@ func (h *apiHandler) login(w http.ResponseWriter, r *http.Request) {
 	h.db.Collection("players").Doc(playerID).Create(r.Context(), nil)
 
 	h.sessions.Put(r.Context(), "user_id", token.UID)
+	if admin, _ := token.Claims["admin"].(bool); admin {
+		h.sessions.Put(r.Context(), "admin", true)
+	}
 	h.sessions.RenewToken(r.Context())
 }
 
@@ -0,0 +1,202 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"cloud.google.com/go/firestore"
+	"github.com/alperenunal/draw2gather/internal/game"
+	"github.com/alperenunal/draw2gather/internal/moderation"
+)
+
+func (h *apiHandler) handleAdminReports(w http.ResponseWriter, r *http.Request) {
+	if !h.sessions.GetBool(r.Context(), "admin") {
+		http.Error(w, http.StatusText(http.StatusForbidden), http.StatusForbidden)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		h.getReports(w, r)
+	case http.MethodPut:
+		h.resolveReport(w, r)
+	default:
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+	}
+}
+
+type getReportsResp struct {
+	Total   int            `json:"total"`
+	Limit   int            `json:"limit"`
+	Offset  int            `json:"offset"`
+	Reports []*game.Report `json:"reports"`
+}
+
+// GET /admin/reports
+func (h *apiHandler) getReports(w http.ResponseWriter, r *http.Request) {
+	limitParam := r.URL.Query().Get("limit")
+	offsetParam := r.URL.Query().Get("offset")
+	statusParam := r.URL.Query().Get("status")
+	if limitParam == "" {
+		limitParam = "20"
+	}
+	if offsetParam == "" {
+		offsetParam = "0"
+	}
+	if statusParam == "" {
+		statusParam = "open"
+	}
+
+	limit, err := strconv.Atoi(limitParam)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	offset, err := strconv.Atoi(offsetParam)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	docs, err := h.db.Collection("reports").
+		Where("status", "==", statusParam).
+		Limit(limit).
+		Offset(offset).
+		Documents(r.Context()).
+		GetAll()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	resp := getReportsResp{
+		Total:   len(docs),
+		Limit:   limit,
+		Offset:  offset,
+		Reports: make([]*game.Report, 0, len(docs)),
+	}
+	for _, doc := range docs {
+		var report game.Report
+		err := doc.DataTo(&report)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		report.ID = doc.Ref.ID
+		resp.Reports = append(resp.Reports, &report)
+	}
+
+	err = json.NewEncoder(w).Encode(&resp)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+}
+
+var (
+	errReportNotFound = errors.New("report not found")
+	errReportResolved = errors.New("report is already resolved")
+)
+
+type resolveReportReq struct {
+	ID string `json:"id"`
+	// Empty dismisses the report
+	Sanction moderation.SanctionType `json:"sanction"`
+	// Duration in seconds, 0 is permanent
+	Duration int `json:"duration"`
+}
+
+// PUT /admin/reports
+func (h *apiHandler) resolveReport(w http.ResponseWriter, r *http.Request) {
+	var req resolveReportReq
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	switch req.Sanction {
+	case "", moderation.Mute, moderation.NoCreate, moderation.Ban:
+	default:
+		http.Error(w, "unknown sanction", http.StatusBadRequest)
+		return
+	}
+	if req.Duration < 0 {
+		http.Error(w, "duration must not be negative", http.StatusBadRequest)
+		return
+	}
+
+	var (
+		ref       = h.db.Collection("reports").Doc(req.ID)
+		sanctions []*moderation.Sanction
+	)
+	// Sanctions are only stored if this request resolves the report
+	err = h.db.RunTransaction(r.Context(), func(ctx context.Context, tx *firestore.Transaction) error {
+		sanctions = nil
+		doc, err := tx.Get(ref)
+		if err != nil && doc == nil {
+			return err
+		}
+		if !doc.Exists() {
+			return errReportNotFound
+		}
+
+		var report game.Report
+		err = doc.DataTo(&report)
+		if err != nil {
+			return err
+		}
+		if report.Status != "open" {
+			return errReportResolved
+		}
+
+		resolution := "dismissed"
+		if req.Sanction != "" {
+			// Sanction the session and, if the player was logged in, the account
+			subjects := map[moderation.SanctionScope]string{
+				moderation.SessionScope: report.Reported,
+				moderation.AccountScope: report.ReportedUser,
+			}
+			for scope, subject := range subjects {
+				if subject == "" {
+					continue
+				}
+
+				sanction := newSanction(scope, subject, req.Sanction, report.Reason, req.Duration)
+				sanction.ReportID = req.ID
+				sanctionRef := h.db.Collection("sanctions").NewDoc()
+				err = tx.Create(sanctionRef, sanction)
+				if err != nil {
+					return err
+				}
+				sanction.ID = sanctionRef.ID
+				sanctions = append(sanctions, sanction)
+			}
+			resolution = string(req.Sanction)
+		}
+
+		return tx.Update(ref, []firestore.Update{
+			{Path: "status", Value: "resolved"},
+			{Path: "resolution", Value: resolution},
+			{Path: "resolved_by", Value: h.sessions.GetString(r.Context(), "user_id")},
+		})
+	})
+	switch {
+	case errors.Is(err, errReportNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	case errors.Is(err, errReportResolved):
+		http.Error(w, err.Error(), http.StatusConflict)
+		return
+	case err != nil:
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	for _, sanction := range sanctions {
+		game.Hub.Sanction(sanction)
+	}
+}
@@ -3,10 +3,13 @@ package api
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"net/http"
+	"strconv"
 
 	"cloud.google.com/go/firestore"
 	"github.com/alperenunal/draw2gather/internal/game"
+	"github.com/alperenunal/draw2gather/internal/moderation"
 )
 
 func (h *apiHandler) handleGame(w http.ResponseWriter, r *http.Request) {
@@ -15,6 +18,8 @@ func (h *apiHandler) handleGame(w http.ResponseWriter, r *http.Request) {
 		h.joinGame(w, r)
 	case http.MethodGet:
 		h.joinServer(w, r)
+	case http.MethodPatch:
+		h.updateGame(w, r)
 	default:
 		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
 	}
@@ -22,6 +27,8 @@ func (h *apiHandler) handleGame(w http.ResponseWriter, r *http.Request) {
 
 type joinGameReq struct {
 	GameID string `json:"game_id"`
+	// Spectators do not take a player slot
+	Spectate bool `json:"spectate"`
 }
 
 // PUT /game
@@ -45,6 +52,16 @@ func (h *apiHandler) joinGame(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	ban, err := h.sanction(r, moderation.Ban)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if ban != nil {
+		http.Error(w, "you are banned", http.StatusForbidden)
+		return
+	}
+
 	doc, err := h.db.Collection("games").Doc(req.GameID).Get(r.Context())
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusNotFound)
@@ -58,6 +75,20 @@ func (h *apiHandler) joinGame(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	for _, p := range g.BannedPlayers {
+		if p == playerID {
+			http.Error(w, "you are banned from this game", http.StatusForbidden)
+			return
+		}
+	}
+
+	if req.Spectate {
+		// Spectators are counted once they connect
+		h.sessions.Put(r.Context(), "game_id", req.GameID)
+		h.sessions.Put(r.Context(), "spectator", true)
+		return
+	}
+
 	if len(g.CurrentPlayers) >= g.MaxPlayers {
 		http.Error(w, "game is full", http.StatusForbidden)
 		return
@@ -71,13 +102,6 @@ func (h *apiHandler) joinGame(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
-	for _, p := range g.BannedPlayers {
-		if p == playerID {
-			http.Error(w, "you are banned from this game", http.StatusForbidden)
-			return
-		}
-	}
-
 	_, err = h.db.Collection("games").Doc(req.GameID).Update(r.Context(), []firestore.Update{
 		{
 			Path: "current_players", Value: firestore.ArrayUnion(name),
@@ -89,6 +113,7 @@ func (h *apiHandler) joinGame(w http.ResponseWriter, r *http.Request) {
 	}
 
 	h.sessions.Put(r.Context(), "game_id", req.GameID)
+	h.sessions.Remove(r.Context(), "spectator")
 }
 
 // GET /game
@@ -107,6 +132,35 @@ func (h *apiHandler) joinServer(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	sanctions, err := moderation.ActiveSanctions(r.Context(), h.db,
+		playerID, h.sessions.GetString(r.Context(), "user_id"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	recentWords, err := game.LoadRecentWords(r.Context(), h.db,
+		playerID, h.sessions.GetString(r.Context(), "user_id"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	requested := 0
+	if v := r.URL.Query().Get("version"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		requested = n
+	}
+	version, err := game.NegotiateVersion(requested)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusUpgradeRequired)
+		return
+	}
+
 	ctx := context.WithoutCancel(r.Context())
 	conn, err := h.ws.Upgrade(w, r, nil)
 	if err != nil {
@@ -114,8 +168,58 @@ func (h *apiHandler) joinServer(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	player := game.NewPlayer(playerID, name, ctx, conn, g)
-	go player.ReadPump()
+	// Clients asking for the binary subprotocol get board actions as binary
+	// frames, everything else stays JSON.
+	encoding := game.EncodingOf(conn.Subprotocol())
+	player := game.NewPlayer(playerID, name, ctx, conn, encoding, version, g)
+	player.ApplySanctions(sanctions)
+	player.SetRecentWords(recentWords)
+	if h.sessions.GetBool(r.Context(), "spectator") {
+		player.Spectate()
+	}
 	go player.WritePump()
+	// Messages of players the game does not know yet are dropped, reading
+	// starts once the player is registered
 	g.Register(player)
+	go player.ReadPump()
+}
+
+// PATCH /game
+func (h *apiHandler) updateGame(w http.ResponseWriter, r *http.Request) {
+	gameID := h.sessions.GetString(r.Context(), "game_id")
+	if gameID == "" {
+		http.Error(w, "not in a game", http.StatusForbidden)
+		return
+	}
+	playerID := h.sessions.GetString(r.Context(), "player_id")
+	userID := h.sessions.GetString(r.Context(), "user_id")
+
+	var req game.SettingsUpdate
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	g := game.Hub.Get(gameID)
+	if g == nil {
+		http.Error(w, "game not found", http.StatusNotFound)
+		return
+	}
+
+	err = g.UpdateSettings(playerID, userID, &req)
+	switch {
+	case errors.Is(err, game.ErrNotOwner):
+		http.Error(w, err.Error(), http.StatusForbidden)
+		return
+	case errors.Is(err, game.ErrWrongState):
+		http.Error(w, err.Error(), http.StatusConflict)
+		return
+	case errors.Is(err, game.ErrGameClosed):
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	case err != nil:
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
 }
@@ -2,11 +2,13 @@ package api
 
 import (
 	"encoding/json"
+	"errors"
+	"fmt"
 	"net/http"
 	"strconv"
 
-	"cloud.google.com/go/firestore"
 	"github.com/alperenunal/draw2gather/internal/game"
+	"github.com/alperenunal/draw2gather/internal/moderation"
 	"github.com/google/uuid"
 )
 
@@ -22,11 +24,30 @@ func (h *apiHandler) handleGames(w http.ResponseWriter, r *http.Request) {
 }
 
 type createGameReq struct {
-	Language    string `json:"language"`
-	WordSet     string `json:"word_set"`
-	MaxPlayers  int    `json:"max_players"`
-	TargetScore int    `json:"target_score"`
-	Visibility  bool   `json:"visibility"`
+	Language    string            `json:"language"`
+	WordSet     string            `json:"word_set"`
+	WordSets    []game.WordSetRef `json:"word_sets"`
+	MaxPlayers  int               `json:"max_players"`
+	TargetScore int               `json:"target_score"`
+	Visibility  bool              `json:"visibility"`
+	// Fraction of guessers needed to skip a drawer, defaults to half
+	SkipThreshold float64 `json:"skip_threshold"`
+	// Turns without activity before an idle player is warned and removed
+	IdleWarning int `json:"idle_warning"`
+	IdleLimit   int `json:"idle_limit"`
+	// Rules of the game, defaults to classic
+	Mode string `json:"mode"`
+	// Number of teams, 0 plays without teams
+	Teams int `json:"teams"`
+	// Words the drawer chooses from, defaults to 3
+	WordChoices int `json:"word_choices"`
+	// How words added in the lobby are used, defaults to mix
+	CustomWordMode string `json:"custom_word_mode"`
+	// Whether every player can add words in the lobby, not only the owner
+	OpenCustomWords bool `json:"open_custom_words"`
+	// Fixed seed to reproduce word and turn order, random if 0. Only admins
+	// can set it, anyone else would know the words in advance.
+	Seed int64 `json:"seed"`
 }
 
 type createGameResp struct {
@@ -47,59 +68,119 @@ func (h *apiHandler) createGame(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	restriction, err := h.sanction(r, moderation.NoCreate)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if restriction != nil {
+		http.Error(w, "you are not allowed to create games", http.StatusForbidden)
+		return
+	}
+
 	var req createGameReq
-	err := json.NewDecoder(r.Body).Decode(&req)
+	err = json.NewDecoder(r.Body).Decode(&req)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
 
-	var (
-		words []string
-		doc   *firestore.DocumentSnapshot
-	)
+	if req.Seed != 0 && !h.sessions.GetBool(r.Context(), "admin") {
+		http.Error(w, "only admins can set the seed", http.StatusForbidden)
+		return
+	}
 
-	if req.WordSet == "default" {
-		doc, err = h.db.Collection("word_sets").Doc(req.Language).Get(r.Context())
-		if err != nil {
-			http.Error(w, err.Error(), http.StatusNotFound)
-			return
-		}
-	} else {
-		userID := h.sessions.GetString(r.Context(), "user_id")
-		if userID == "" {
-			http.Error(w, "user_id is required", http.StatusUnauthorized)
-			return
-		}
+	if req.SkipThreshold == 0 {
+		req.SkipThreshold = game.DefaultSkipThreshold
+	}
+	if req.SkipThreshold < 0 || req.SkipThreshold > 1 {
+		http.Error(w, "skip_threshold must be between 0 and 1", http.StatusBadRequest)
+		return
+	}
 
-		doc, err = h.db.Collection("players").Doc(userID).Collection("word_sets").Doc(req.WordSet).Get(r.Context())
-		if err != nil {
-			http.Error(w, err.Error(), http.StatusNotFound)
-			return
-		}
+	if req.IdleLimit == 0 {
+		req.IdleLimit = game.DefaultIdleLimit
+	}
+	if req.IdleWarning == 0 {
+		req.IdleWarning = min(game.DefaultIdleWarning, req.IdleLimit)
+	}
+	if req.IdleWarning < 0 || req.IdleWarning > req.IdleLimit {
+		http.Error(w, "idle_warning must be between 1 and idle_limit", http.StatusBadRequest)
+		return
 	}
 
-	var wordSet wordSetObject
-	err = doc.DataTo(&wordSet)
-	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+	if !game.ValidTeams(req.Teams) {
+		http.Error(w, "teams must be 0 or between 2 and 4", http.StatusBadRequest)
+		return
+	}
+	if req.WordChoices == 0 {
+		req.WordChoices = game.DefaultWordChoices
+	}
+	if !game.ValidWordChoices(req.WordChoices) {
+		http.Error(w, fmt.Sprintf("word_choices must be between 2 and %d", game.MaxWordChoices), http.StatusBadRequest)
+		return
+	}
+	if req.CustomWordMode == "" {
+		req.CustomWordMode = game.MixCustomWords
+	}
+	if !game.ValidCustomWordMode(req.CustomWordMode) {
+		http.Error(w, "unknown custom_word_mode", http.StatusBadRequest)
+		return
+	}
+	if req.Mode == "" {
+		req.Mode = game.ClassicMode
+	}
+	if !game.ValidMode(req.Mode) {
+		http.Error(w, "unknown mode", http.StatusBadRequest)
 		return
 	}
-	if wordSet.Language != req.Language {
-		http.Error(w, "word set language does not match game language", http.StatusBadRequest)
+	if req.Mode != game.ClassicMode && req.Teams > 0 {
+		http.Error(w, "teams are only played in classic mode", http.StatusBadRequest)
+		return
+	}
+
+	if len(req.WordSets) == 0 {
+		req.WordSets = []game.WordSetRef{{Name: req.WordSet}}
+	}
+	words, wordSets, err := game.LoadWordSets(r.Context(), h.db, req.Language, req.WordSets,
+		h.sessions.GetString(r.Context(), "user_id"))
+	switch {
+	case errors.Is(err, game.ErrInvalidWordSets):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	case errors.Is(err, game.ErrLoginRequired):
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return
+	case errors.Is(err, game.ErrWordSetNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	case errors.Is(err, game.ErrLanguageMismatch):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	case err != nil:
+		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
-	words = wordSet.Words
 
 	id := uuid.NewString()
 	_, err = h.db.Collection("games").Doc(id).Create(r.Context(), &gameObject{
-		Owner:          playerID,
-		Visibility:     req.Visibility,
-		Language:       req.Language,
-		TargetScore:    req.TargetScore,
-		MaxPlayers:     req.MaxPlayers,
-		CurrentPlayers: []string{},
-		BannedPlayers:  []string{},
+		Owner:           playerID,
+		Visibility:      req.Visibility,
+		Language:        req.Language,
+		WordSet:         req.WordSet,
+		WordSets:        wordSets,
+		TargetScore:     req.TargetScore,
+		MaxPlayers:      req.MaxPlayers,
+		SkipThreshold:   req.SkipThreshold,
+		IdleWarning:     req.IdleWarning,
+		IdleLimit:       req.IdleLimit,
+		Mode:            req.Mode,
+		Teams:           req.Teams,
+		WordChoices:     req.WordChoices,
+		CustomWordMode:  req.CustomWordMode,
+		OpenCustomWords: req.OpenCustomWords,
+		CurrentPlayers:  []string{},
+		BannedPlayers:   []string{},
 	})
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
@@ -115,12 +196,25 @@ func (h *apiHandler) createGame(w http.ResponseWriter, r *http.Request) {
 	}
 
 	settings := &game.GameSettings{
-		ID:          id,
-		Owner:       playerID,
-		TargetScore: req.TargetScore,
-		Words:       words,
-		DB:          h.db,
-		Sessions:    h.sessions,
+		ID:              id,
+		Owner:           playerID,
+		TargetScore:     req.TargetScore,
+		MaxPlayers:      req.MaxPlayers,
+		Visibility:      req.Visibility,
+		SkipThreshold:   req.SkipThreshold,
+		IdleWarning:     req.IdleWarning,
+		IdleLimit:       req.IdleLimit,
+		Mode:            req.Mode,
+		Teams:           req.Teams,
+		WordChoices:     req.WordChoices,
+		CustomWordMode:  req.CustomWordMode,
+		OpenCustomWords: req.OpenCustomWords,
+		Language:        req.Language,
+		WordSets:        wordSets,
+		Words:           words,
+		Seed:            req.Seed,
+		DB:              h.db,
+		Sessions:        h.sessions,
 	}
 	g := game.NewGame(settings)
 	go g.Run()
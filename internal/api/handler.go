@@ -10,6 +10,7 @@ import (
 	"firebase.google.com/go/v4/auth"
 	scsfs "github.com/alexedwards/scs/firestore"
 	"github.com/alexedwards/scs/v2"
+	"github.com/alperenunal/draw2gather/internal/game"
 	"github.com/gorilla/websocket"
 	"github.com/rs/cors"
 )
@@ -47,7 +48,8 @@ func NewHandler(app *firebase.App) (http.Handler, error) {
 	sessions.Cookie.Secure = true
 
 	ws := websocket.Upgrader{
-		CheckOrigin: func(r *http.Request) bool { return true },
+		Subprotocols: game.Subprotocols,
+		CheckOrigin:  func(r *http.Request) bool { return true },
 	}
 
 	h := &apiHandler{
@@ -62,8 +64,12 @@ func NewHandler(app *firebase.App) (http.Handler, error) {
 	mux.HandleFunc("/login", h.handleLogin)
 	mux.HandleFunc("/logout", h.handleLogout)
 	mux.HandleFunc("/set", h.handleSet)
+	mux.HandleFunc("/set/", h.handleSetByName)
 	mux.HandleFunc("/games", h.handleGames)
 	mux.HandleFunc("/game", h.handleGame)
+	mux.HandleFunc("/report", h.handleReport)
+	mux.HandleFunc("/admin/reports", h.handleAdminReports)
+	mux.HandleFunc("/admin/sanctions", h.handleAdminSanctions)
 	mux.HandleFunc("/health", h.handleHealth)
 
 	handler := http.Handler(mux)
@@ -71,7 +77,8 @@ func NewHandler(app *firebase.App) (http.Handler, error) {
 	handler = cors.New(cors.Options{
 		AllowedOrigins:   []string{"https://draw2gather.online", "https://www.draw2gather.online"},
 		AllowedHeaders:   []string{"*"},
-		AllowedMethods:   []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
+		AllowedMethods:   []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"},
+		ExposedHeaders:   []string{"ETag"},
 		AllowCredentials: true,
 	}).Handler(handler)
 
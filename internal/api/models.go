@@ -1,14 +1,27 @@
 package api
 
+import "github.com/alperenunal/draw2gather/internal/game"
+
 type gameObject struct {
-	ID             string   `firestore:"-" json:"id"`
-	Owner          string   `firestore:"owner" json:"-"`
-	Visibility     bool     `firestore:"visibility" json:"visibility"`
-	Language       string   `firestore:"language" json:"language"`
-	TargetScore    int      `firestore:"target_score" json:"target_score"`
-	MaxPlayers     int      `firestore:"max_players" json:"max_players"`
-	CurrentPlayers []string `firestore:"current_players" json:"current_players"`
-	BannedPlayers  []string `firestore:"banned_players" json:"-"`
+	ID              string            `firestore:"-" json:"id"`
+	Owner           string            `firestore:"owner" json:"-"`
+	Visibility      bool              `firestore:"visibility" json:"visibility"`
+	Language        string            `firestore:"language" json:"language"`
+	WordSet         string            `firestore:"word_set" json:"word_set"`
+	WordSets        []game.WordSetRef `firestore:"word_sets" json:"word_sets"`
+	TargetScore     int               `firestore:"target_score" json:"target_score"`
+	MaxPlayers      int               `firestore:"max_players" json:"max_players"`
+	SkipThreshold   float64           `firestore:"skip_threshold" json:"skip_threshold"`
+	IdleWarning     int               `firestore:"idle_warning" json:"idle_warning"`
+	IdleLimit       int               `firestore:"idle_limit" json:"idle_limit"`
+	Mode            string            `firestore:"mode" json:"mode"`
+	Teams           int               `firestore:"teams" json:"teams"`
+	WordChoices     int               `firestore:"word_choices" json:"word_choices"`
+	CustomWordMode  string            `firestore:"custom_word_mode" json:"custom_word_mode"`
+	OpenCustomWords bool              `firestore:"open_custom_words" json:"open_custom_words"`
+	CurrentPlayers  []string          `firestore:"current_players" json:"current_players"`
+	Spectators      int               `firestore:"spectators" json:"spectators"`
+	BannedPlayers   []string          `firestore:"banned_players" json:"-"`
 }
 
 type userObject struct {
@@ -18,4 +31,8 @@ type wordSetObject struct {
 	Name     string   `firestore:"name" json:"name"`
 	Language string   `firestore:"language" json:"language"`
 	Words    []string `firestore:"words" json:"words"`
+	// Public sets can be used in games of other users
+	Public bool `firestore:"public" json:"public"`
+	// Version is increased on every change and sent as the ETag
+	Version int `firestore:"version" json:"version"`
 }
@@ -0,0 +1,81 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/alperenunal/draw2gather/internal/game"
+)
+
+func (h *apiHandler) handleReport(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodPost:
+		h.createReport(w, r)
+	default:
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+	}
+}
+
+type createReportReq struct {
+	GameID   string `json:"game_id"`
+	PlayerID string `json:"player_id"`
+	Reason   string `json:"reason"`
+}
+
+type createReportResp struct {
+	ID string `json:"id"`
+}
+
+// POST /report
+func (h *apiHandler) createReport(w http.ResponseWriter, r *http.Request) {
+	playerID := h.sessions.GetString(r.Context(), "player_id")
+	if playerID == "" {
+		http.Error(w, "player_id is required", http.StatusUnauthorized)
+		return
+	}
+
+	var req createReportReq
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// Reports can also be sent after the game is over
+	if req.GameID == "" {
+		req.GameID = h.sessions.GetString(r.Context(), "game_id")
+	}
+	if req.GameID == "" || req.PlayerID == "" {
+		http.Error(w, "game_id and player_id are required", http.StatusBadRequest)
+		return
+	}
+	if req.PlayerID == playerID {
+		http.Error(w, "cannot report yourself", http.StatusBadRequest)
+		return
+	}
+
+	report, err := game.NewReport(req.GameID, playerID, req.PlayerID, req.Reason)
+	if errors.Is(err, game.ErrNotInGame) {
+		http.Error(w, err.Error(), http.StatusForbidden)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	err = game.StoreReport(r.Context(), h.db, report)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	err = json.NewEncoder(w).Encode(&createReportResp{
+		ID: report.ID,
+	})
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+}
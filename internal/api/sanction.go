@@ -0,0 +1,176 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/alperenunal/draw2gather/internal/game"
+	"github.com/alperenunal/draw2gather/internal/moderation"
+)
+
+func (h *apiHandler) handleAdminSanctions(w http.ResponseWriter, r *http.Request) {
+	if !h.sessions.GetBool(r.Context(), "admin") {
+		http.Error(w, http.StatusText(http.StatusForbidden), http.StatusForbidden)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		h.getSanctions(w, r)
+	case http.MethodPost:
+		h.createSanction(w, r)
+	case http.MethodDelete:
+		h.deleteSanction(w, r)
+	default:
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+	}
+}
+
+func newSanction(scope moderation.SanctionScope, subject string, typ moderation.SanctionType, reason string, duration int) *moderation.Sanction {
+	now := time.Now()
+	s := &moderation.Sanction{
+		Scope:     scope,
+		Subject:   subject,
+		Type:      typ,
+		Reason:    reason,
+		CreatedAt: now,
+	}
+	if duration > 0 {
+		s.ExpiresAt = now.Add(time.Duration(duration) * time.Second)
+	}
+	return s
+}
+
+// storeSanction saves a sanction and enforces it in running games.
+func (h *apiHandler) storeSanction(ctx context.Context, s *moderation.Sanction) error {
+	ref, _, err := h.db.Collection("sanctions").Add(ctx, s)
+	if err != nil {
+		return err
+	}
+	s.ID = ref.ID
+	game.Hub.Sanction(s)
+	return nil
+}
+
+// sanction returns the active sanction of the requesting session that
+// restricts t, or nil.
+func (h *apiHandler) sanction(r *http.Request, t moderation.SanctionType) (*moderation.Sanction, error) {
+	playerID := h.sessions.GetString(r.Context(), "player_id")
+	userID := h.sessions.GetString(r.Context(), "user_id")
+	if playerID == "" && userID == "" {
+		return nil, nil
+	}
+
+	sanctions, err := moderation.ActiveSanctions(r.Context(), h.db, playerID, userID)
+	if err != nil {
+		return nil, err
+	}
+	return moderation.Find(sanctions, t), nil
+}
+
+type getSanctionsResp struct {
+	Total     int                    `json:"total"`
+	Sanctions []*moderation.Sanction `json:"sanctions"`
+}
+
+// GET /admin/sanctions
+func (h *apiHandler) getSanctions(w http.ResponseWriter, r *http.Request) {
+	playerID := r.URL.Query().Get("player_id")
+	userID := r.URL.Query().Get("user_id")
+	if playerID == "" && userID == "" {
+		http.Error(w, "player_id or user_id is required", http.StatusBadRequest)
+		return
+	}
+
+	sanctions, err := moderation.ActiveSanctions(r.Context(), h.db, playerID, userID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	err = json.NewEncoder(w).Encode(&getSanctionsResp{
+		Total:     len(sanctions),
+		Sanctions: sanctions,
+	})
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+}
+
+type createSanctionReq struct {
+	Scope   moderation.SanctionScope `json:"scope"`
+	Subject string                   `json:"subject"`
+	Type    moderation.SanctionType  `json:"type"`
+	Reason  string                   `json:"reason"`
+	// Duration in seconds, 0 is permanent
+	Duration int `json:"duration"`
+}
+
+type createSanctionResp struct {
+	ID string `json:"id"`
+}
+
+// POST /admin/sanctions
+func (h *apiHandler) createSanction(w http.ResponseWriter, r *http.Request) {
+	var req createSanctionReq
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	switch req.Scope {
+	case moderation.SessionScope, moderation.AccountScope:
+	default:
+		http.Error(w, "unknown scope", http.StatusBadRequest)
+		return
+	}
+	switch req.Type {
+	case moderation.Mute, moderation.NoCreate, moderation.Ban:
+	default:
+		http.Error(w, "unknown sanction", http.StatusBadRequest)
+		return
+	}
+	if req.Subject == "" {
+		http.Error(w, "subject is required", http.StatusBadRequest)
+		return
+	}
+	if req.Duration < 0 {
+		http.Error(w, "duration must not be negative", http.StatusBadRequest)
+		return
+	}
+
+	sanction := newSanction(req.Scope, req.Subject, req.Type, req.Reason, req.Duration)
+	err = h.storeSanction(r.Context(), sanction)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	err = json.NewEncoder(w).Encode(&createSanctionResp{
+		ID: sanction.ID,
+	})
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+}
+
+// DELETE /admin/sanctions
+func (h *apiHandler) deleteSanction(w http.ResponseWriter, r *http.Request) {
+	id := r.URL.Query().Get("id")
+	if id == "" {
+		http.Error(w, "id is required", http.StatusBadRequest)
+		return
+	}
+
+	_, err := h.db.Collection("sanctions").Doc(id).Delete(r.Context())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	game.Hub.LiftSanction(id)
+}
@@ -6,6 +6,7 @@ import (
 	"math/rand"
 	"net/http"
 
+	"github.com/alperenunal/draw2gather/internal/moderation"
 	"github.com/google/uuid"
 )
 
@@ -35,8 +36,18 @@ func (h *apiHandler) createUser(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	ban, err := h.sanction(r, moderation.Ban)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if ban != nil {
+		http.Error(w, "you are banned", http.StatusForbidden)
+		return
+	}
+
 	var req createUserReq
-	err := json.NewDecoder(r.Body).Decode(&req)
+	err = json.NewDecoder(r.Body).Decode(&req)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
@@ -47,6 +58,12 @@ func (h *apiHandler) createUser(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	res := moderation.Name().Check(req.Name)
+	if res.Verdict == moderation.Reject {
+		http.Error(w, "name is not allowed", http.StatusBadRequest)
+		return
+	}
+
 	if h.sessions.GetString(r.Context(), "player_id") == "" {
 		h.sessions.Put(r.Context(), "player_id", uuid.NewString())
 	}
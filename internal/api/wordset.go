@@ -1,10 +1,18 @@
 package api
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"net/http"
+	"slices"
+	"strconv"
+	"strings"
 
 	"cloud.google.com/go/firestore"
+	"github.com/alperenunal/draw2gather/internal/game"
+	"github.com/alperenunal/draw2gather/internal/moderation"
 )
 
 func (h *apiHandler) handleSet(w http.ResponseWriter, r *http.Request) {
@@ -22,6 +30,7 @@ type createWordSetReq struct {
 	Name     string   `json:"name"`
 	Language string   `json:"language"`
 	Words    []string `json:"words"`
+	Public   bool     `json:"public"`
 }
 
 func (h *apiHandler) createWordSet(w http.ResponseWriter, r *http.Request) {
@@ -38,18 +47,15 @@ func (h *apiHandler) createWordSet(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if req.Name == "" {
-		http.Error(w, "name is required", http.StatusBadRequest)
-		return
-	}
-
-	if req.Name == "default" {
-		http.Error(w, "default is a reserved name", http.StatusBadRequest)
+	err = validateWordSetName(req.Name)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
 
-	if len(req.Words) < 50 {
-		http.Error(w, "at least 50 words are required", http.StatusBadRequest)
+	words, err := cleanWords(req.Language, req.Words)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
 
@@ -58,7 +64,9 @@ func (h *apiHandler) createWordSet(w http.ResponseWriter, r *http.Request) {
 		Create(r.Context(), &wordSetObject{
 			Name:     req.Name,
 			Language: req.Language,
-			Words:    req.Words,
+			Words:    words,
+			Public:   req.Public,
+			Version:  1,
 		})
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusBadRequest)
@@ -117,3 +125,317 @@ func (h *apiHandler) getWordSets(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 }
+
+const minWords = 50
+
+var (
+	errWordSetExists   = errors.New("a word set with this name already exists")
+	errVersionMismatch = errors.New("word set was changed, reload it and try again")
+	errLanguageChanged = errors.New("language of a word set cannot be changed")
+)
+
+func validateWordSetName(name string) error {
+	switch {
+	case name == "":
+		return errors.New("name is required")
+	case name == "default":
+		return errors.New("default is a reserved name")
+	case strings.Contains(name, "/"):
+		return errors.New("name cannot contain /")
+	}
+	return nil
+}
+
+// cleanWords normalizes the words of a set and drops duplicates, the set
+// must still have enough words in a supported language.
+func cleanWords(language string, words []string) ([]string, error) {
+	if !slices.Contains(moderation.Languages, language) {
+		return nil, errors.New("unsupported language")
+	}
+
+	filter := moderation.Word(language)
+	cleaned := make([]string, 0, len(words))
+	seen := make(map[string]struct{}, len(words))
+	for _, word := range words {
+		word = game.NormalizeWord(word)
+		if word == "" {
+			continue
+		}
+		if _, ok := seen[word]; ok {
+			continue
+		}
+		if res := filter.Check(word); res.Verdict == moderation.Reject {
+			return nil, fmt.Errorf("word %q is not allowed", word)
+		}
+		seen[word] = struct{}{}
+		cleaned = append(cleaned, word)
+	}
+
+	if len(cleaned) < minWords {
+		return nil, fmt.Errorf("at least %d different words are required", minWords)
+	}
+	return cleaned, nil
+}
+
+func (h *apiHandler) handleSetByName(w http.ResponseWriter, r *http.Request) {
+	userID := h.sessions.GetString(r.Context(), "user_id")
+	if userID == "" {
+		http.Error(w, "user_id is required", http.StatusUnauthorized)
+		return
+	}
+
+	name := strings.TrimPrefix(r.URL.Path, "/set/")
+	if name == "" || strings.Contains(name, "/") {
+		http.NotFound(w, r)
+		return
+	}
+	ref := h.db.Collection("players").Doc(userID).Collection("word_sets").Doc(name)
+
+	switch r.Method {
+	case http.MethodGet:
+		h.getWordSet(w, r, ref)
+	case http.MethodPut:
+		h.updateWordSet(w, r, ref)
+	case http.MethodPatch:
+		h.patchWordSet(w, r, ref)
+	case http.MethodDelete:
+		h.deleteWordSet(w, r, ref)
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// anyVersion is the version of If-Match: *, it matches any existing set.
+const anyVersion = -1
+
+// ifMatch returns the version the client last read, changes without it
+// are rejected so they cannot overwrite changes made in between. ok is
+// false if an error was written.
+func ifMatch(w http.ResponseWriter, r *http.Request) (version int, ok bool) {
+	v := r.Header.Get("If-Match")
+	switch v {
+	case "":
+		http.Error(w, "If-Match is required", http.StatusPreconditionRequired)
+		return 0, false
+	case "*":
+		return anyVersion, true
+	}
+
+	version, err := strconv.Atoi(strings.Trim(strings.TrimPrefix(v, "W/"), `"`))
+	if err != nil || version < 0 {
+		http.Error(w, "If-Match must be an ETag of the word set", http.StatusBadRequest)
+		return 0, false
+	}
+	return version, true
+}
+
+func writeWordSet(w http.ResponseWriter, ws *wordSetObject) {
+	w.Header().Set("ETag", strconv.Quote(strconv.Itoa(ws.Version)))
+	err := json.NewEncoder(w).Encode(ws)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+}
+
+// changeWordSet runs change on the word set in a transaction if it is still
+// at the given version, change gets the set with its version already
+// increased and writes it.
+func (h *apiHandler) changeWordSet(ctx context.Context, ref *firestore.DocumentRef, version int, change func(tx *firestore.Transaction, ws *wordSetObject) error) (*wordSetObject, error) {
+	var ws wordSetObject
+	err := h.db.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		doc, err := tx.Get(ref)
+		if err != nil && doc == nil {
+			return err
+		}
+		if !doc.Exists() {
+			return game.ErrWordSetNotFound
+		}
+		ws = wordSetObject{}
+		err = doc.DataTo(&ws)
+		if err != nil {
+			return err
+		}
+		if version != anyVersion && ws.Version != version {
+			return errVersionMismatch
+		}
+		ws.Version++
+		return change(tx, &ws)
+	})
+	return &ws, err
+}
+
+func wordSetError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, game.ErrWordSetNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	case errors.Is(err, errVersionMismatch):
+		http.Error(w, err.Error(), http.StatusPreconditionFailed)
+	case errors.Is(err, errWordSetExists):
+		http.Error(w, err.Error(), http.StatusConflict)
+	case errors.Is(err, errLanguageChanged):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	case errors.As(err, new(*badWordsError)):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	default:
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+type badWordsError struct {
+	err error
+}
+
+func (e *badWordsError) Error() string {
+	return e.err.Error()
+}
+
+// GET /set/{name}
+func (h *apiHandler) getWordSet(w http.ResponseWriter, r *http.Request, ref *firestore.DocumentRef) {
+	doc, err := ref.Get(r.Context())
+	if err != nil {
+		if doc != nil && !doc.Exists() {
+			http.Error(w, game.ErrWordSetNotFound.Error(), http.StatusNotFound)
+			return
+		}
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	var ws wordSetObject
+	err = doc.DataTo(&ws)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	writeWordSet(w, &ws)
+}
+
+type updateWordSetReq struct {
+	// New name of the set, empty keeps the name
+	Name     string   `json:"name"`
+	Language string   `json:"language"`
+	Words    []string `json:"words"`
+	Public   bool     `json:"public"`
+}
+
+// PUT /set/{name}
+func (h *apiHandler) updateWordSet(w http.ResponseWriter, r *http.Request, ref *firestore.DocumentRef) {
+	version, ok := ifMatch(w, r)
+	if !ok {
+		return
+	}
+
+	var req updateWordSetReq
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if req.Name == "" {
+		req.Name = ref.ID
+	}
+	err = validateWordSetName(req.Name)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	ws, err := h.changeWordSet(r.Context(), ref, version, func(tx *firestore.Transaction, ws *wordSetObject) error {
+		if req.Language != "" && req.Language != ws.Language {
+			return errLanguageChanged
+		}
+		words, err := cleanWords(ws.Language, req.Words)
+		if err != nil {
+			return &badWordsError{err}
+		}
+
+		ws.Words = words
+		ws.Public = req.Public
+		if req.Name == ref.ID {
+			return tx.Set(ref, ws)
+		}
+
+		// Renaming moves the set to a new document
+		newRef := ref.Parent.Doc(req.Name)
+		doc, err := tx.Get(newRef)
+		if err != nil && doc == nil {
+			return err
+		}
+		if doc.Exists() {
+			return errWordSetExists
+		}
+		ws.Name = req.Name
+		err = tx.Create(newRef, ws)
+		if err != nil {
+			return err
+		}
+		return tx.Delete(ref)
+	})
+	if err != nil {
+		wordSetError(w, err)
+		return
+	}
+	writeWordSet(w, ws)
+}
+
+type patchWordSetReq struct {
+	Add    []string `json:"add"`
+	Remove []string `json:"remove"`
+}
+
+// PATCH /set/{name}
+func (h *apiHandler) patchWordSet(w http.ResponseWriter, r *http.Request, ref *firestore.DocumentRef) {
+	version, ok := ifMatch(w, r)
+	if !ok {
+		return
+	}
+
+	var req patchWordSetReq
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	ws, err := h.changeWordSet(r.Context(), ref, version, func(tx *firestore.Transaction, ws *wordSetObject) error {
+		remove := make(map[string]struct{}, len(req.Remove))
+		for _, word := range req.Remove {
+			remove[game.NormalizeWord(word)] = struct{}{}
+		}
+		words := slices.DeleteFunc(append(ws.Words, req.Add...), func(word string) bool {
+			_, ok := remove[game.NormalizeWord(word)]
+			return ok
+		})
+		words, err := cleanWords(ws.Language, words)
+		if err != nil {
+			return &badWordsError{err}
+		}
+
+		ws.Words = words
+		return tx.Set(ref, ws)
+	})
+	if err != nil {
+		wordSetError(w, err)
+		return
+	}
+	writeWordSet(w, ws)
+}
+
+// DELETE /set/{name}
+func (h *apiHandler) deleteWordSet(w http.ResponseWriter, r *http.Request, ref *firestore.DocumentRef) {
+	version, ok := ifMatch(w, r)
+	if !ok {
+		return
+	}
+
+	_, err := h.changeWordSet(r.Context(), ref, version, func(tx *firestore.Transaction, ws *wordSetObject) error {
+		return tx.Delete(ref)
+	})
+	if err != nil {
+		wordSetError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,92 @@
+package game
+
+import (
+	"math"
+	"math/rand"
+	"slices"
+)
+
+// wordBag hands out every word once in a shuffled order before any word
+// repeats. Words with a higher weight tend to come up earlier. The order only
+// depends on the words and the game's RNG.
+type wordBag struct {
+	rng     *rand.Rand
+	words   []string
+	weights map[string]float64
+	queue   []string
+}
+
+func newWordBag(words map[string]float64, rng *rand.Rand) *wordBag {
+	b := &wordBag{
+		rng:     rng,
+		words:   make([]string, 0, len(words)),
+		weights: words,
+	}
+	for word := range words {
+		b.words = append(b.words, word)
+	}
+	// Map order is random, sorting keeps the bag reproducible from the seed
+	slices.Sort(b.words)
+	b.refill()
+	return b
+}
+
+func (b *wordBag) size() int {
+	return len(b.words)
+}
+
+// refill shuffles the words by weight, each word gets the key u^(1/weight)
+// for a random u and the words are sorted by key. Equal weights give a
+// uniform shuffle.
+func (b *wordBag) refill() {
+	keys := make(map[string]float64, len(b.words))
+	for _, word := range b.words {
+		keys[word] = math.Pow(b.rng.Float64(), 1/b.weights[word])
+	}
+
+	b.queue = slices.Clone(b.words)
+	slices.SortStableFunc(b.queue, func(a, c string) int {
+		switch {
+		case keys[a] > keys[c]:
+			return -1
+		case keys[a] < keys[c]:
+			return 1
+		default:
+			return 0
+		}
+	})
+}
+
+// take removes the first word that ok accepts from the bag, the bag is
+// refilled once it runs out.
+func (b *wordBag) take(ok func(string) bool) (string, bool) {
+	if len(b.queue) == 0 {
+		b.refill()
+	}
+
+	i := slices.IndexFunc(b.queue, ok)
+	if i < 0 {
+		return "", false
+	}
+	word := b.queue[i]
+	b.queue = slices.Delete(b.queue, i, i+1)
+	return word, true
+}
+
+// putBack returns words that were offered but not drawn, they come up again
+// after the rest of the bag.
+func (b *wordBag) putBack(words ...string) {
+	for _, word := range words {
+		// The bag may have been refilled since the word was taken
+		if !slices.Contains(b.queue, word) {
+			b.queue = append(b.queue, word)
+		}
+	}
+}
+
+// remove takes a word out of the bag until the next refill.
+func (b *wordBag) remove(word string) {
+	b.queue = slices.DeleteFunc(b.queue, func(w string) bool {
+		return w == word
+	})
+}
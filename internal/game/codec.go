@@ -0,0 +1,199 @@
+package game
+
+import (
+	"encoding/binary"
+	"errors"
+	"math"
+)
+
+type Encoding int
+
+const (
+	JSONEncoding Encoding = iota
+	BinaryEncoding
+)
+
+const (
+	JSONSubprotocol   = "draw2gather.json"
+	BinarySubprotocol = "draw2gather.binary"
+)
+
+// Subprotocols are offered during the websocket upgrade in order of preference.
+var Subprotocols = []string{BinarySubprotocol, JSONSubprotocol}
+
+var errShortFrame = errors.New("short binary frame")
+
+func EncodingOf(subprotocol string) Encoding {
+	if subprotocol == BinarySubprotocol {
+		return BinaryEncoding
+	}
+	return JSONEncoding
+}
+
+func isBoardAction(act action) bool {
+	switch act {
+	case draw, erase, lineDraw, rectDraw, rectFill, circleDraw, circleFill,
+		ellipseDraw, ellipseFill, polygonDraw, polygonFill, curveDraw,
+		changeColor, changeAlphaColor, changePencilSize, changeEraserSize, clearBoard:
+		return true
+	default:
+		return false
+	}
+}
+
+// encodeBinary writes a board action as a binary frame. A frame starts with
+// the action as an uvarint. Coordinates are written as zigzag varints, each
+// one relative to the previous value on the same axis. Payloads without
+// coordinates are appended as JSON.
+func encodeBinary(m *Message) ([]byte, error) {
+	in, err := m.decodeMessage()
+	if err != nil {
+		return nil, err
+	}
+
+	buf := binary.AppendUvarint(nil, uint64(m.Action))
+
+	switch p := in.(type) {
+	case payload[[]int]:
+		buf = binary.AppendUvarint(buf, uint64(len(p.Value)))
+		for i, v := range p.Value {
+			prev := 0
+			if i >= 2 {
+				prev = p.Value[i-2]
+			}
+			buf = binary.AppendVarint(buf, int64(v-prev))
+		}
+	case payload[pointsPayload]:
+		buf = appendPoints(buf, []point{p.Value.Start, p.Value.End})
+	case payload[polygonPayload]:
+		buf = binary.AppendUvarint(buf, uint64(len(p.Value.Points)))
+		buf = appendPoints(buf, p.Value.Points)
+	case payload[curvePayload]:
+		buf = binary.AppendUvarint(buf, math.Float64bits(p.Value.Tension))
+		buf = binary.AppendUvarint(buf, uint64(len(p.Value.Points)))
+		buf = appendPoints(buf, p.Value.Points)
+	default:
+		buf = append(buf, m.Payload...)
+	}
+
+	return buf, nil
+}
+
+func decodeBinary(data []byte) (*Message, error) {
+	r := &frameReader{data: data}
+	act := action(r.uvarint())
+	if r.err != nil {
+		return nil, r.err
+	}
+	if !isBoardAction(act) {
+		return nil, errInvalidAction
+	}
+
+	var msg *Message
+	switch act {
+	case draw, erase:
+		n := r.count()
+		values := make([]int, 0, n)
+		for i := 0; i < n; i++ {
+			prev := 0
+			if i >= 2 {
+				prev = values[i-2]
+			}
+			values = append(values, prev+int(r.varint()))
+		}
+		msg = newMessage(act, values)
+
+	case lineDraw, rectDraw, rectFill, circleDraw, circleFill:
+		points := r.points(2)
+		msg = newMessage(act, pointsPayload{Start: points[0], End: points[1]})
+
+	case polygonDraw, polygonFill:
+		points := r.points(r.count())
+		msg = newMessage(act, polygonPayload{Points: points})
+
+	case curveDraw:
+		tension := math.Float64frombits(r.uvarint())
+		points := r.points(r.count())
+		msg = newMessage(act, curvePayload{Points: points, Tension: tension})
+
+	default:
+		msg = &Message{Action: act, Payload: string(r.rest())}
+	}
+
+	if r.err != nil {
+		return nil, r.err
+	}
+	return msg, nil
+}
+
+func appendPoints(buf []byte, points []point) []byte {
+	prev := point{}
+	for _, p := range points {
+		buf = binary.AppendVarint(buf, int64(p.X-prev.X))
+		buf = binary.AppendVarint(buf, int64(p.Y-prev.Y))
+		prev = p
+	}
+	return buf
+}
+
+type frameReader struct {
+	data []byte
+	err  error
+}
+
+func (r *frameReader) uvarint() uint64 {
+	if r.err != nil {
+		return 0
+	}
+	v, n := binary.Uvarint(r.data)
+	if n <= 0 {
+		r.err = errShortFrame
+		return 0
+	}
+	r.data = r.data[n:]
+	return v
+}
+
+func (r *frameReader) varint() int64 {
+	if r.err != nil {
+		return 0
+	}
+	v, n := binary.Varint(r.data)
+	if n <= 0 {
+		r.err = errShortFrame
+		return 0
+	}
+	r.data = r.data[n:]
+	return v
+}
+
+// count reads a length prefix, every element takes at least one byte so
+// longer counts than the remaining data are rejected.
+func (r *frameReader) count() int {
+	n := r.uvarint()
+	if n > uint64(len(r.data)) {
+		r.err = errShortFrame
+		return 0
+	}
+	return int(n)
+}
+
+func (r *frameReader) points(n int) []point {
+	points := make([]point, 0, n)
+	prev := point{}
+	for i := 0; i < n; i++ {
+		p := point{
+			X: prev.X + int(r.varint()),
+			Y: prev.Y + int(r.varint()),
+		}
+		points = append(points, p)
+		prev = p
+	}
+	return points
+}
+
+func (r *frameReader) rest() []byte {
+	data := r.data
+	r.data = nil
+	return data
+}
@@ -0,0 +1,114 @@
+package game
+
+import (
+	"log/slog"
+	"slices"
+	"strings"
+
+	"github.com/alperenunal/draw2gather/internal/moderation"
+)
+
+const maxCustomWords = 100
+
+// How custom words are mixed with the word set
+const (
+	MixCustomWords      = "mix"
+	PriorityCustomWords = "priority"
+	OnlyCustomWords     = "only"
+)
+
+func ValidCustomWordMode(mode string) bool {
+	switch mode {
+	case MixCustomWords, PriorityCustomWords, OnlyCustomWords:
+		return true
+	default:
+		return false
+	}
+}
+
+// NormalizeWord lowercases a word and collapses its whitespace so words and
+// guesses compare equal however they were typed.
+func NormalizeWord(word string) string {
+	return strings.ToLower(strings.Join(strings.Fields(word), " "))
+}
+
+func (g *Game) isCustomWord(word string) bool {
+	return slices.Contains(g.customWords, word)
+}
+
+// resetWords refills the bag from the word set and the custom words.
+func (g *Game) resetWords() {
+	words := make(map[string]float64)
+	if g.customWordMode != OnlyCustomWords {
+		for word, weight := range g.dictionary {
+			words[word] = weight
+		}
+	}
+	for _, word := range g.customWords {
+		words[word] = max(words[word], 1)
+	}
+	g.words = newWordBag(words, g.rng)
+}
+
+func (g *Game) handleAddWords(m *Message) error {
+	if m.player.ID != g.owner && !g.openCustomWords {
+		return errNotOwner
+	}
+
+	in, err := m.decodeMessage()
+	if err != nil {
+		return err
+	}
+	p := in.(payload[[]string])
+
+	filter := moderation.Word(g.language)
+	added := []string{}
+	for _, word := range p.Value {
+		word = NormalizeWord(word)
+		if word == "" || filter.Check(word).Verdict == moderation.Reject {
+			return errInvalidWord
+		}
+		if g.isCustomWord(word) || slices.Contains(added, word) {
+			continue
+		}
+		added = append(added, word)
+	}
+	if len(g.customWords)+len(added) > maxCustomWords {
+		return errTooManyWords
+	}
+	if len(added) == 0 {
+		return nil
+	}
+
+	g.customWords = append(g.customWords, added...)
+	g.resetWords()
+
+	g.logger.Info("Custom words added", slog.String("player", m.player.ID), slog.Any("words", added))
+	g.sendToAll(newMessage(customWords, g.customWords))
+	return nil
+}
+
+func (g *Game) handleRemoveWords(m *Message) error {
+	if m.player.ID != g.owner {
+		return errNotOwner
+	}
+
+	in, err := m.decodeMessage()
+	if err != nil {
+		return err
+	}
+	p := in.(payload[[]string])
+
+	removed := make([]string, 0, len(p.Value))
+	for _, word := range p.Value {
+		removed = append(removed, NormalizeWord(word))
+	}
+	g.customWords = slices.DeleteFunc(g.customWords, func(word string) bool {
+		return slices.Contains(removed, word)
+	})
+	g.resetWords()
+
+	g.logger.Info("Custom words removed", slog.String("player", m.player.ID), slog.Any("words", removed))
+	g.sendToAll(newMessage(customWords, g.customWords))
+	return nil
+}
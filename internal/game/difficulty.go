@@ -0,0 +1,252 @@
+package game
+
+import (
+	"context"
+	"log/slog"
+	"net/url"
+	"unicode/utf8"
+
+	"cloud.google.com/go/firestore"
+)
+
+const (
+	DefaultWordChoices = 3
+	MaxWordChoices     = 5
+
+	// Turns a word needs before its guess rate decides its tier
+	minStatTurns = 5
+)
+
+type tier string
+
+const (
+	easy   tier = "easy"
+	medium tier = "medium"
+	hard   tier = "hard"
+)
+
+var tiers = []tier{easy, medium, hard}
+
+// multiplier scales the points of a turn in percent.
+func (t tier) multiplier() int {
+	switch t {
+	case medium:
+		return 150
+	case hard:
+		return 200
+	default:
+		return 100
+	}
+}
+
+type wordChoice struct {
+	Word       string `json:"word"`
+	Tier       tier   `json:"tier"`
+	Multiplier int    `json:"multiplier"`
+}
+
+// wordStat counts how often a word of a default set was drawn and guessed,
+// across games of the same language. Each word has its own document so
+// games do not contend for one document.
+type wordStat struct {
+	Word    string `firestore:"word"`
+	Turns   int    `firestore:"turns"`
+	Guessed int    `firestore:"guessed"`
+}
+
+func (g *Game) wordStatRef(word string) *firestore.DocumentRef {
+	return g.db.Collection("word_stats").Doc(g.language).
+		Collection("words").Doc(url.PathEscape(word))
+}
+
+func ValidWordChoices(n int) bool {
+	return n >= 2 && n <= MaxWordChoices
+}
+
+// loadWordStats reads the guess rates of the game's language, without them
+// tiers fall back to word length. Games with a fixed seed do not use them,
+// the stats change between runs.
+func (g *Game) loadWordStats() {
+	g.wordStats = make(map[string]wordStat)
+	if g.fixedSeed {
+		return
+	}
+
+	docs, err := g.db.Collection("word_stats").Doc(g.language).
+		Collection("words").Documents(context.Background()).GetAll()
+	if err != nil {
+		g.logger.Error(err.Error())
+		return
+	}
+	for _, doc := range docs {
+		var s wordStat
+		if err := doc.DataTo(&s); err != nil {
+			g.logger.Error(err.Error())
+			continue
+		}
+		g.wordStats[s.Word] = s
+	}
+}
+
+func (g *Game) tierOf(word string) tier {
+	if s, ok := g.wordStats[word]; ok && s.Turns >= minStatTurns {
+		rate := float64(s.Guessed) / float64(s.Turns)
+		switch {
+		case rate >= 0.6:
+			return easy
+		case rate >= 0.3:
+			return medium
+		default:
+			return hard
+		}
+	}
+
+	switch n := utf8.RuneCountInString(word); {
+	case n <= 5:
+		return easy
+	case n <= 8:
+		return medium
+	default:
+		return hard
+	}
+}
+
+// recordWord stores whether the word of the finished turn was guessed. Only
+// words of the default set are recorded, other sets are private.
+func (g *Game) recordWord(word string, guessed bool) {
+	if _, ok := g.sharedWords[word]; !ok {
+		return
+	}
+
+	s := g.wordStats[word]
+	s.Word = word
+	s.Turns++
+	update := map[string]any{"word": word, "turns": firestore.Increment(1)}
+	if guessed {
+		s.Guessed++
+		update["guessed"] = firestore.Increment(1)
+	}
+	g.wordStats[word] = s
+
+	ref := g.wordStatRef(word)
+	go func() {
+		_, err := ref.Set(context.Background(), update, firestore.MergeAll)
+		if err != nil {
+			g.logger.Error("Failed to record word", slog.String("word", word), slog.String("error", err.Error()))
+		}
+	}()
+}
+
+// pickWords offers one word per tier, easy first, and repeats the tiers for
+// more choices. Words no player saw recently are preferred and a tier
+// without words left is filled with any word. Excluded choices are never
+// offered.
+func (g *Game) pickWords(exclude ...wordChoice) []wordChoice {
+	choices := make([]wordChoice, 0, g.wordChoices)
+	taken := func(word string) bool {
+		for _, list := range [][]wordChoice{choices, exclude} {
+			for _, c := range list {
+				if c.Word == word {
+					return true
+				}
+			}
+		}
+		return false
+	}
+
+	for i := 0; i < g.wordChoices; i++ {
+		t := tiers[i%len(tiers)]
+		var (
+			word string
+			ok   bool
+		)
+		accepts := []func(string) bool{
+			func(word string) bool { return g.tierOf(word) == t && !g.recentlySeen(word) },
+			func(word string) bool { return g.tierOf(word) == t },
+			func(word string) bool { return !g.recentlySeen(word) },
+			func(word string) bool { return true },
+		}
+		if g.customWordMode == PriorityCustomWords {
+			// Custom words are drawn before any word of the set
+			custom := make([]func(string) bool, 0, len(accepts))
+			for _, accept := range accepts {
+				accept := accept
+				custom = append(custom, func(word string) bool { return g.isCustomWord(word) && accept(word) })
+			}
+			accepts = append(custom, accepts...)
+		}
+		for _, accept := range accepts {
+			word, ok = g.words.take(func(word string) bool {
+				return !taken(word) && accept(word)
+			})
+			if ok {
+				break
+			}
+		}
+		if !ok {
+			break
+		}
+
+		t = g.tierOf(word)
+		choices = append(choices, wordChoice{Word: word, Tier: t, Multiplier: t.multiplier()})
+	}
+	return choices
+}
+
+// sendWordChoices offers the choices to the drawers, older clients get the
+// first two words.
+func (g *Game) sendWordChoices() {
+	var words [2]string
+	for i := 0; i < len(words) && i < len(g.choices); i++ {
+		words[i] = g.choices[i].Word
+	}
+
+	for _, drawer := range g.drawers {
+		if drawer.supports(wordChoices) {
+			g.sendToPlayer(drawer, newMessage(wordChoices, g.choices))
+		} else {
+			g.sendToPlayer(drawer, newMessage(pick, words))
+		}
+	}
+}
+
+func (g *Game) handleReroll(m *Message) error {
+	if !g.isDrawer(m.player) {
+		return errNotYourTurn
+	}
+	if g.rerolled {
+		return errAlreadyRerolled
+	}
+	if g.paused() {
+		return errPaused
+	}
+
+	// The old choices can come up again in later turns, but not instead of
+	// themselves
+	old := g.choices
+	for _, c := range old {
+		g.words.putBack(c.Word)
+	}
+	choices := g.pickWords(old...)
+	if len(choices) < g.wordChoices {
+		// The drawer keeps the old choices
+		for _, c := range choices {
+			g.words.putBack(c.Word)
+		}
+		for _, c := range old {
+			g.words.remove(c.Word)
+		}
+		return errNotEnoughWords
+	}
+
+	g.rerolled = true
+	g.choices = choices
+	m.player.active = true
+	g.sendWordChoices()
+	return nil
+}
+
+// points scales the points of the current turn by the word's tier.
+func (g *Game) points(n int) int {
+	return n * g.currentTier.multiplier() / 100
+}
@@ -1,7 +1,77 @@
 package game
 
-import "errors"
+import (
+	"encoding/json"
+	"errors"
+)
+
+// gameError is an error that is reported back to the client, code is
+// machine-readable and stable across versions.
+type gameError struct {
+	code    string
+	message string
+}
+
+func newGameError(code, message string) *gameError {
+	return &gameError{code: code, message: message}
+}
+
+func (e *gameError) Error() string {
+	return e.message
+}
 
 var (
-	errInvalidAction = errors.New("invalid action")
+	errInvalidAction    = newGameError("invalid_action", "invalid action")
+	errInvalidPayload   = newGameError("invalid_payload", "invalid payload")
+	errWrongState       = newGameError("wrong_state", "action not allowed in current state")
+	errNotYourTurn      = newGameError("not_your_turn", "not your turn")
+	errNotOwner         = newGameError("not_owner", "not owner")
+	errAlreadyAnswered  = newGameError("already_answered", "already answered")
+	errPlayerNotFound   = newGameError("player_not_found", "player not found")
+	errCannotKickOwner  = newGameError("cannot_kick_owner", "cannot kick owner")
+	errNotEnoughPlayers = newGameError("not_enough_players", "not enough players")
+	errRateLimited      = newGameError("rate_limited", "too many messages")
+	errMessageRejected  = newGameError("message_rejected", "message rejected by moderation")
+	errMuted            = newGameError("muted", "you are muted")
+	errCannotReportSelf = newGameError("cannot_report_self", "cannot report yourself")
+	errInvalidTarget    = newGameError("invalid_target", "invalid target")
+	errVoteInProgress   = newGameError("vote_in_progress", "another vote is in progress")
+	errVoteCooldown     = newGameError("vote_cooldown", "cannot start a vote now")
+	errCannotVote       = newGameError("cannot_vote", "you cannot vote")
+	errInvalidSettings  = newGameError("invalid_settings", "invalid settings")
+	errPaused           = newGameError("paused", "game is paused")
+	errNotPaused        = newGameError("not_paused", "game is not paused")
+	errSpectator        = newGameError("spectator", "spectators cannot do this")
+	errGameFull         = newGameError("game_full", "game is full")
+	errNameTaken        = newGameError("name_taken", "name already taken")
+	errTeamsDisabled    = newGameError("teams_disabled", "game is not played in teams")
+	errInvalidTeam      = newGameError("invalid_team", "invalid team")
+	errNotYourTeam      = newGameError("not_your_team", "only the drawer's team can guess")
+	errDrawerGuess      = newGameError("drawer_cannot_guess", "drawers cannot guess")
+	errAlreadyRerolled  = newGameError("already_rerolled", "words can be rerolled once per turn")
+	errInvalidWord      = newGameError("invalid_word", "word is not allowed")
+	errTooManyWords     = newGameError("too_many_words", "too many custom words")
+	errNotEnoughWords   = newGameError("not_enough_words", "not enough words")
+
+	errReadFailed = errors.New("read failed")
+
+	// Errors checked outside of the game loop
+	ErrNotOwner   = errNotOwner
+	ErrWrongState = errWrongState
 )
+
+func errorCode(err error) string {
+	var (
+		gameErr   *gameError
+		syntaxErr *json.SyntaxError
+		typeErr   *json.UnmarshalTypeError
+	)
+	switch {
+	case errors.As(err, &gameErr):
+		return gameErr.code
+	case errors.As(err, &syntaxErr), errors.As(err, &typeErr):
+		return errInvalidPayload.code
+	default:
+		return "internal_error"
+	}
+}
@@ -2,20 +2,23 @@ package game
 
 import (
 	"context"
-	"errors"
 	"fmt"
 	"log/slog"
+	"math/rand"
 	"os"
 	"slices"
 	"strings"
 	"sync"
+	"time"
 
 	"cloud.google.com/go/firestore"
 	"github.com/alexedwards/scs/v2"
+	"github.com/alperenunal/draw2gather/internal/moderation"
 )
 
 const (
-	gameLogPath = "./logs/games/%s.log"
+	gameLogPath        = "./logs/games/%s.log"
+	receivedMessageLog = "Received message"
 )
 
 type Game struct {
@@ -25,41 +28,151 @@ type Game struct {
 	sessions *scs.SessionManager
 	logFile  *os.File
 	logger   *slog.Logger
+	filter   *moderation.Pipeline
 
 	closed   bool
 	closedMu sync.Mutex
 	ch       chan *Message
 	register chan *Player
+	events   chan func() state
+	done     chan struct{}
 
 	state   state
-	stateCh chan state
+	stateCh chan timeout
+
+	mode  gameMode
+	relay *relay
 
 	targetScore int
-	words       map[string]struct{}
-	dictionary  map[string]struct{}
+	maxPlayers  int
+	visibility  bool
+	language    string
+	wordSets    []WordSetRef
+	rng         *rand.Rand
+	seed        int64
+	fixedSeed   bool
+	words       *wordBag
+	dictionary  map[string]float64
+	// Words of the default set, their stats are shared between games
+	sharedWords map[string]struct{}
+	wordStats   map[string]wordStat
+	wordChoices int
+	choices     []wordChoice
+	drawnWords  []string
+	rerolled    bool
+	// Whether the current turn got past picking
+	wordPicked  bool
 	players     map[string]*Player
 	playerQueue []*Player
+	spectators  map[string]*Player
 
 	currentPlayer   *Player
+	drawers         []*Player
 	currentWord     string
+	currentTier     tier
 	commands        []*Message
 	answeredPlayers map[*Player]struct{}
+	turnStarted     time.Time
+
+	customWords     []string
+	customWordMode  string
+	openCustomWords bool
+
+	kickVote        *kickVote
+	voteCooldownEnd time.Time
+	skipThreshold   float64
+	skipVotes       map[*Player]struct{}
+
+	idleWarning int
+	idleLimit   int
+
+	// Number of teams, 0 plays without teams
+	teams      int
+	teamScores []int
+	lastTeam   int
+
+	// Set while the game is paused, fires when the pause runs out
+	pauseTimer *time.Timer
 }
 
 type GameSettings struct {
 	ID          string
 	Owner       string
 	TargetScore int
-	Words       []string
-	DB          *firestore.Client
-	Sessions    *scs.SessionManager
+	MaxPlayers  int
+	Visibility  bool
+	// Fraction of guessers that must vote to skip a drawer
+	SkipThreshold float64
+	// Turns without any message before a player is warned and removed
+	IdleWarning int
+	IdleLimit   int
+	Mode        string
+	Teams       int
+	WordChoices int
+	Language    string
+	WordSets    []WordSetRef
+	// Words of the word sets
+	Words *Words
+	// How custom words added in the lobby are used and whether every
+	// player can add them
+	CustomWordMode  string
+	OpenCustomWords bool
+	// Seed of the game's RNG, a random seed is used if 0
+	Seed     int64
+	DB       *firestore.Client
+	Sessions *scs.SessionManager
+}
+
+// setWords normalizes the words of the game's word sets, words that become
+// equal keep the highest weight.
+func (g *Game) setWords(words *Words) {
+	g.dictionary = make(map[string]float64)
+	for word, weight := range words.Weights {
+		word = NormalizeWord(word)
+		if word != "" {
+			g.dictionary[word] = max(g.dictionary[word], weight)
+		}
+	}
+
+	g.sharedWords = make(map[string]struct{})
+	for _, word := range words.Default {
+		g.sharedWords[NormalizeWord(word)] = struct{}{}
+	}
 }
 
 func NewGame(settings *GameSettings) *Game {
-	words := make(map[string]struct{})
-	for _, word := range settings.Words {
-		word = strings.ToLower(word)
-		words[word] = struct{}{}
+	seed := settings.Seed
+	if seed == 0 {
+		seed = rand.Int63()
+	}
+	rng := rand.New(rand.NewSource(seed))
+
+	skipThreshold := settings.SkipThreshold
+	if skipThreshold <= 0 {
+		skipThreshold = DefaultSkipThreshold
+	}
+
+	idleWarning, idleLimit := settings.IdleWarning, settings.IdleLimit
+	if idleLimit <= 0 {
+		idleLimit = DefaultIdleLimit
+	}
+	if idleWarning <= 0 || idleWarning > idleLimit {
+		idleWarning = min(DefaultIdleWarning, idleLimit)
+	}
+
+	wordChoices := settings.WordChoices
+	if !ValidWordChoices(wordChoices) {
+		wordChoices = DefaultWordChoices
+	}
+
+	customWordMode := settings.CustomWordMode
+	if !ValidCustomWordMode(customWordMode) {
+		customWordMode = MixCustomWords
+	}
+
+	mode, ok := modes[settings.Mode]
+	if !ok {
+		mode = classicMode{}
 	}
 
 	logFile := fmt.Sprintf(gameLogPath, settings.ID)
@@ -67,70 +180,157 @@ func NewGame(settings *GameSettings) *Game {
 	if err != nil {
 		return nil
 	}
-	logger := slog.New(slog.NewTextHandler(file, nil))
+	// Logs are JSON so reports can read context back from them
+	logger := slog.New(slog.NewJSONHandler(file, nil))
 
-	return &Game{
+	g := &Game{
 		id:       settings.ID,
 		owner:    settings.Owner,
 		db:       settings.DB,
 		sessions: settings.Sessions,
 		logFile:  file,
 		logger:   logger,
+		filter:   moderation.Chat(settings.Language),
 
 		closed:   false,
 		closedMu: sync.Mutex{},
 		ch:       make(chan *Message),
 		register: make(chan *Player),
+		events:   make(chan func() state),
+		done:     make(chan struct{}),
 
 		state:   &waitingState{},
-		stateCh: make(chan state),
+		stateCh: make(chan timeout),
+
+		mode: mode,
 
 		targetScore: settings.TargetScore,
-		dictionary:  words,
-		words:       words,
+		maxPlayers:  settings.MaxPlayers,
+		visibility:  settings.Visibility,
+		language:    settings.Language,
+		wordSets:    settings.WordSets,
+		rng:         rng,
+		seed:        seed,
+		fixedSeed:   settings.Seed != 0,
+		wordChoices: wordChoices,
 		players:     make(map[string]*Player),
 		playerQueue: []*Player{},
+		spectators:  make(map[string]*Player),
 
 		currentPlayer:   nil,
 		currentWord:     "",
 		commands:        []*Message{},
 		answeredPlayers: make(map[*Player]struct{}),
+
+		customWords:     []string{},
+		customWordMode:  customWordMode,
+		openCustomWords: settings.OpenCustomWords,
+
+		skipThreshold: skipThreshold,
+		skipVotes:     make(map[*Player]struct{}),
+
+		idleWarning: idleWarning,
+		idleLimit:   idleLimit,
+
+		teams:      settings.Teams,
+		teamScores: make([]int, settings.Teams),
 	}
+	g.setWords(settings.Words)
+	g.resetWords()
+	g.loadWordStats()
+	// Replaying a game needs the seed, the settings and the same messages.
+	// Recent words and word stats are not logged, games with a fixed seed
+	// do not use them.
+	g.logger.Info("Game created", slog.Int64("seed", seed), slog.Bool("fixed_seed", g.fixedSeed),
+		slog.String("mode", g.mode.name()),
+		slog.String("language", g.language), slog.Any("word_sets", g.wordSets))
+	return g
 }
 
 func (g *Game) Register(p *Player) {
-	g.register <- p
+	select {
+	case g.register <- p:
+	case <-g.done:
+		// The write pump closes the connection
+		close(p.ch)
+	}
 }
 
 func (g *Game) Run() {
-	for {
+	defer close(g.done)
+
+	for !g.Closed() {
 		select {
 		case p := <-g.register:
 			g.handleJoin(p)
 
-		case msg, ok := <-g.ch:
-			if !ok {
-				g.delete()
-				return
+		case msg := <-g.ch:
+			// Players that left may still have messages on the way
+			if !g.inGame(msg.player) {
+				continue
 			}
 
-			g.logger.Info("Received message",
+			g.logger.Info(receivedMessageLog, slog.String("player", msg.player.ID),
 				slog.Int("action", int(msg.Action)), slog.String("payload", msg.Payload))
-			state := g.state.HandleMessage(g, msg)
-			if state != nil {
-				g.state.Exit(g)
-				g.state = state
-				g.state.Enter(g)
+			if msg.player.Spectator && !isSpectatorAction(msg.Action) {
+				g.reject(msg, errSpectator)
+				continue
 			}
+			g.transition(g.state.HandleMessage(g, msg))
 
-		case state := <-g.stateCh:
-			if state != nil {
-				g.state.Exit(g)
-				g.state = state
-				g.state.Enter(g)
+		case fn := <-g.events:
+			g.transition(fn())
+
+		case t := <-g.stateCh:
+			// A timer that fired while the state was left is stale, one that
+			// fired as the game was paused runs again on resume
+			if t.from == g.state && !g.paused() {
+				g.transition(t.next)
 			}
 		}
 	}
+	g.delete()
+}
+
+func (g *Game) inGame(p *Player) bool {
+	return g.players[p.ID] == p || g.spectators[p.ID] == p
+}
+
+// send hands a message to the game loop, it is dropped once the game is over.
+func (g *Game) send(m *Message) {
+	select {
+	case g.ch <- m:
+	case <-g.done:
+	}
+}
+
+func (g *Game) transition(state state) {
+	if state == nil {
+		return
+	}
+
+	// Leaving a state ends the pause, the next state starts its own timer
+	paused := g.paused()
+	if paused {
+		g.pauseTimer.Stop()
+		g.pauseTimer = nil
+	}
+
+	g.state.Exit(g)
+	g.state = state
+	g.state.Enter(g)
+
+	if paused {
+		g.sendPauseStatus()
+	}
+}
+
+// post runs fn on the game loop, it must not be called from the loop itself.
+func (g *Game) post(fn func() state) {
+	select {
+	case g.events <- fn:
+	case <-g.done:
+	}
 }
 
 func (g *Game) Closed() bool {
@@ -143,7 +343,6 @@ func (g *Game) close() {
 	g.closedMu.Lock()
 	defer g.closedMu.Unlock()
 	g.closed = true
-	close(g.ch)
 }
 
 func (g *Game) delete() {
@@ -162,50 +361,61 @@ func (g *Game) sendExceptPlayer(player *Player, m *Message) {
 			p.ch <- m
 		}
 	}
+	for _, p := range g.spectators {
+		if p != player {
+			p.ch <- m
+		}
+	}
 }
 
 func (g *Game) sendToAll(m *Message) {
 	for _, p := range g.players {
 		p.ch <- m
 	}
+	for _, p := range g.spectators {
+		p.ch <- m
+	}
 }
 
-func (g *Game) pickPlayer() *Player {
-	p := g.playerQueue[0]
-	g.playerQueue = g.playerQueue[1:]
-	g.playerQueue = append(g.playerQueue, p)
-	return p
-}
-
-func (g *Game) pickWords() [2]string {
-	var words [2]string
-	for i := 0; i < 2; i++ {
-		for word := range g.words {
-			words[i] = word
-			delete(g.words, word)
-			break
-		}
+// reject logs why a message was ignored and tells its sender.
+func (g *Game) reject(m *Message, err error) {
+	g.logger.Error(err.Error(), slog.Int("action", int(m.Action)))
+	if m.player.quitted {
+		return
 	}
-	g.words[words[0]] = struct{}{}
-	g.words[words[1]] = struct{}{}
-	return words
+
+	msg := newMessage(errorReply, errorPayload{
+		Code:    errorCode(err),
+		Message: err.Error(),
+		Action:  m.Action,
+	})
+	g.sendToPlayer(m.player, msg)
 }
 
 func (g *Game) getNextPoint() int {
 	return 10 - len(g.answeredPlayers)
 }
 
+// disconnect stops sending to p, its write pump sends what is left and
+// closes the connection, which ends the read pump.
+func (g *Game) disconnect(p *Player) {
+	p.quitted = true
+	close(p.ch)
+}
+
 func (g *Game) removePlayer(player *Player) (state, error) {
 	if _, ok := g.players[player.ID]; !ok {
-		return nil, errors.New("player not found")
+		return nil, errPlayerNotFound
 	}
 
 	g.sessions.Remove(player.ctx, "game_id")
 	g.sessions.Commit(player.ctx)
 
-	player.quitted = true
 	delete(g.players, player.ID)
-	close(player.ch)
+	g.disconnect(player)
+	if g.kickVote != nil && g.kickVote.target == player {
+		g.endKickVote(false)
+	}
 	g.playerQueue = slices.DeleteFunc(g.playerQueue, func(p *Player) bool {
 		return p == player
 	})
@@ -225,34 +435,70 @@ func (g *Game) removePlayer(player *Player) (state, error) {
 	g.sendToAll(msg)
 
 	if player.ID == g.owner {
-		owner := ""
-		for id := range g.players {
-			owner = id
-			break
-		}
-
-		g.owner = owner
-		msg := newMessage(newOwner, owner)
-		g.sendToAll(msg)
+		g.setOwner(g.successor())
 	}
 
-	if len(g.players) == 1 {
+	if len(g.players) < g.mode.minPlayers() {
 		return &waitingState{}, nil
 	}
 	if g.currentPlayer == player {
 		return &startingState{}, nil
 	}
+	// The turn goes on as long as the leading drawer is there
+	g.drawers = slices.DeleteFunc(g.drawers, func(p *Player) bool {
+		return p == player
+	})
 
 	return nil, nil
 }
 
+func (g *Game) handleFlood(p *Player, act action, strikes int) state {
+	if p.quitted {
+		return nil
+	}
+
+	if strikes >= maxStrikes {
+		g.logger.Warn("Kicking flooding player", slog.String("player", p.ID))
+		g.sendToPlayer(p, newEmptyMessage(kick))
+		state, err := g.remove(p)
+		if err != nil {
+			g.logger.Error(err.Error())
+		}
+		return state
+	}
+
+	g.reject(&Message{player: p, Action: act}, errRateLimited)
+	return nil
+}
+
+func (g *Game) addPlayer(p *Player) {
+	p.active = true
+	p.joinedAt = time.Now()
+	if g.teams > 0 {
+		p.Team = g.smallestTeam()
+	}
+	g.players[p.ID] = p
+	g.playerQueue = append(g.playerQueue, p)
+}
+
 func (g *Game) handleJoin(p *Player) {
 	if _, ok := g.players[p.ID]; ok {
 		return
 	}
+	if _, ok := g.spectators[p.ID]; ok {
+		return
+	}
 
-	g.players[p.ID] = p
-	g.playerQueue = append(g.playerQueue, p)
+	if p.Spectator {
+		g.spectators[p.ID] = p
+		g.db.Collection("games").Doc(g.id).Update(context.Background(), []firestore.Update{
+			{Path: "spectators", Value: firestore.Increment(1)},
+		})
+	} else {
+		g.addPlayer(p)
+	}
+	g.logger.Info("Player joined", slog.String("player", p.ID), slog.String("name", p.Name),
+		slog.String("user", g.sessions.GetString(p.ctx, "user_id")), slog.Bool("spectator", p.Spectator))
 
 	var greetPayload gamePayload
 
@@ -267,6 +513,10 @@ func (g *Game) handleJoin(p *Player) {
 		greetPayload.State = "drawing"
 	case *endingState:
 		greetPayload.State = "ending"
+	case *relayRoundState:
+		greetPayload.State = "relay"
+	case *relayRevealState:
+		greetPayload.State = "reveal"
 	default:
 		return
 	}
@@ -276,8 +526,20 @@ func (g *Game) handleJoin(p *Player) {
 	if g.currentPlayer != nil {
 		greetPayload.CurrentPlayer = g.currentPlayer.ID
 	}
+	if len(g.drawers) > 1 {
+		greetPayload.Drawers = g.drawerIDs()
+	}
 	greetPayload.Players = g.players
-	greetPayload.Commands = g.commands
+	greetPayload.Spectators = g.spectators
+	greetPayload.Commands = slices.DeleteFunc(slices.Clone(g.commands), func(m *Message) bool {
+		return !p.supports(m.Action)
+	})
+	greetPayload.Settings = g.settings()
+	greetPayload.TeamScores = g.teamScores
+	greetPayload.CustomWords = g.customWords
+	greetPayload.Paused = g.paused()
+	greetPayload.Protocol = p.version
+	greetPayload.Capabilities = p.capabilities()
 
 	msg := newMessage(greet, greetPayload)
 	g.sendToPlayer(p, msg)
@@ -287,12 +549,20 @@ func (g *Game) handleJoin(p *Player) {
 }
 
 func (g *Game) handleQuit(m *Message) (state, error) {
-	return g.removePlayer(m.player)
+	return g.remove(m.player)
+}
+
+// remove takes a player or a spectator out of the game.
+func (g *Game) remove(p *Player) (state, error) {
+	if p.Spectator {
+		return nil, g.removeSpectator(p)
+	}
+	return g.removePlayer(p)
 }
 
 func (g *Game) handleKick(m *Message) (state, error) {
 	if m.player.ID != g.owner {
-		return nil, errors.New("not owner")
+		return nil, errNotOwner
 	}
 
 	in, err := m.decodeMessage()
@@ -302,30 +572,149 @@ func (g *Game) handleKick(m *Message) (state, error) {
 	p := in.(payload[string])
 
 	if p.Value == g.owner {
-		return nil, errors.New("cannot kick owner")
+		return nil, errCannotKickOwner
 	}
 
-	if player, ok := g.players[p.Value]; !ok {
-		return nil, errors.New("player not found")
-	} else {
-		g.db.Collection("games").Doc(g.id).Update(context.Background(), []firestore.Update{
-			{Path: "banned_players", Value: firestore.ArrayUnion(player.ID)},
+	player, ok := g.players[p.Value]
+	if !ok {
+		player, ok = g.spectators[p.Value]
+	}
+	if !ok {
+		return nil, errPlayerNotFound
+	}
+	g.banPlayer(player)
+	g.sendToPlayer(player, newEmptyMessage(kick))
+	return g.remove(player)
+}
+
+// banPlayer keeps the player from joining this game again.
+func (g *Game) banPlayer(player *Player) {
+	g.db.Collection("games").Doc(g.id).Update(context.Background(), []firestore.Update{
+		{Path: "banned_players", Value: firestore.ArrayUnion(player.ID)},
+	})
+}
+
+func (g *Game) handleReport(m *Message) error {
+	in, err := m.decodeMessage()
+	if err != nil {
+		return err
+	}
+	p := in.(payload[reportPayload])
+
+	if _, ok := g.players[p.Value.Player]; !ok {
+		return errPlayerNotFound
+	}
+	if p.Value.Player == m.player.ID {
+		return errCannotReportSelf
+	}
+
+	// Reading the log and storing the report would hold up the game, the
+	// result is posted back to the loop
+	go func() {
+		r, err := NewReport(g.id, m.player.ID, p.Value.Player, p.Value.Reason)
+		if err == nil {
+			err = StoreReport(context.Background(), g.db, r)
+		}
+		g.post(func() state {
+			if err != nil {
+				g.reject(m, err)
+				return nil
+			}
+			g.logger.Info("Player reported", slog.String("report", r.ID),
+				slog.String("reporter", r.Reporter), slog.String("reported", r.Reported))
+			return nil
 		})
-		g.sendToPlayer(player, newEmptyMessage(kick))
-		return g.removePlayer(player)
+	}()
+	return nil
+}
+
+// liftSanction unmutes players muted by the sanction, bans already removed
+// the player.
+func (g *Game) liftSanction(id string) {
+	for _, players := range []map[string]*Player{g.players, g.spectators} {
+		for _, p := range players {
+			if p.mute != nil && p.mute.ID == id {
+				p.mute = nil
+			}
+		}
+	}
+}
+
+// applySanction enforces a sanction on a player if they are in this game.
+func (g *Game) applySanction(s *moderation.Sanction) state {
+	var p *Player
+	for _, player := range g.players {
+		if s.Matches(player.ID, g.sessions.GetString(player.ctx, "user_id")) {
+			p = player
+			break
+		}
+	}
+	for _, spectator := range g.spectators {
+		if s.Matches(spectator.ID, g.sessions.GetString(spectator.ctx, "user_id")) {
+			p = spectator
+			break
+		}
+	}
+	if p == nil {
+		return nil
+	}
+	if p.Spectator && s.Restricts(moderation.Ban) {
+		g.sendToPlayer(p, newEmptyMessage(kick))
+		err := g.removeSpectator(p)
+		if err != nil {
+			g.logger.Error(err.Error())
+		}
+		return nil
+	}
+
+	switch s.Type {
+	case moderation.Mute:
+		p.mute = s
+		return nil
+	case moderation.Ban:
+		g.sendToPlayer(p, newEmptyMessage(kick))
+		state, err := g.removePlayer(p)
+		if err != nil {
+			g.logger.Error(err.Error())
+		}
+		return state
+	default:
+		return nil
+	}
+}
+
+// moderate runs text sent by a player through the chat filters.
+func (g *Game) moderate(p *Player, text string) (string, error) {
+	res := g.filter.Check(text)
+	if len(res.Flags) > 0 {
+		g.logger.Warn("Moderated message", slog.String("player", p.ID),
+			slog.String("message", text), slog.Any("flags", res.Flags))
+	}
+	if res.Verdict == moderation.Reject || strings.TrimSpace(res.Text) == "" {
+		return "", errMessageRejected
 	}
+	return res.Text, nil
 }
 
 func (g *Game) handleChat(m *Message) error {
+	if m.player.isMuted() {
+		return errMuted
+	}
+
 	in, err := m.decodeMessage()
 	if err != nil {
 		return err
 	}
 	p := in.(payload[string])
 
+	text, err := g.moderate(m.player, p.Value)
+	if err != nil {
+		return err
+	}
+
 	msg := newMessage(chat, messagePayload{
 		Player:  m.player.ID,
-		Message: p.Value,
+		Message: text,
 	})
 	g.sendToAll(msg)
 
@@ -334,19 +723,25 @@ func (g *Game) handleChat(m *Message) error {
 
 func (g *Game) handleStart(m *Message) (state, error) {
 	if m.player.ID != g.owner {
-		return nil, errors.New("not owner")
+		return nil, errNotOwner
 	}
 
-	if len(g.players) < 2 {
-		return nil, errors.New("not enough players")
+	if len(g.players) < g.mode.minPlayers() {
+		return nil, errNotEnoughPlayers
 	}
-
-	return &startingState{}, nil
+	// Turn order is shuffled each game instead of following join order
+	g.rng.Shuffle(len(g.playerQueue), func(i, j int) {
+		g.playerQueue[i], g.playerQueue[j] = g.playerQueue[j], g.playerQueue[i]
+	})
+	return g.mode.start(g)
 }
 
 func (g *Game) handlePick(m *Message) (state, error) {
-	if m.player != g.currentPlayer {
-		return nil, errors.New("not your turn")
+	if !g.isDrawer(m.player) {
+		return nil, errNotYourTurn
+	}
+	if g.paused() {
+		return nil, errPaused
 	}
 
 	in, err := m.decodeMessage()
@@ -355,29 +750,77 @@ func (g *Game) handlePick(m *Message) (state, error) {
 	}
 	p := in.(payload[string])
 
+	i := slices.IndexFunc(g.choices, func(c wordChoice) bool {
+		return c.Word == p.Value
+	})
+	if i < 0 {
+		return nil, errInvalidPayload
+	}
+
 	g.currentWord = p.Value
-	delete(g.words, p.Value)
+	g.currentTier = g.choices[i].Tier
+	g.choices = slices.Delete(g.choices, i, i+1)
+	g.drawnWords = append(g.drawnWords, p.Value)
+	m.player.active = true
+
+	// The other drawers did not see the choices
+	for _, drawer := range g.drawers {
+		if drawer != m.player {
+			g.sendToPlayer(drawer, newMessage(drawerWord, p.Value))
+		}
+	}
 
 	return &drawingState{}, nil
 }
 
+func (g *Game) isDrawer(p *Player) bool {
+	return slices.Contains(g.drawers, p)
+}
+
+func (g *Game) drawerIDs() []string {
+	ids := make([]string, 0, len(g.drawers))
+	for _, p := range g.drawers {
+		ids = append(ids, p.ID)
+	}
+	return ids
+}
+
 func (g *Game) handleBoardAction(m *Message) error {
-	if m.player != g.currentPlayer {
-		return errors.New("not your turn")
+	if !g.isDrawer(m.player) {
+		return errNotYourTurn
+	}
+	if g.paused() {
+		return errPaused
 	}
 	if _, err := m.decodeMessage(); err != nil {
 		return err
 	}
 
+	// With several drawers clients keep the tools of each drawer apart
+	m.Drawer = ""
+	if len(g.drawers) > 1 {
+		m.Drawer = m.player.ID
+	}
+
 	g.commands = append(g.commands, m)
 	g.sendExceptPlayer(m.player, m)
+	m.player.active = true
 
 	return nil
 }
 
 func (g *Game) handleGuess(m *Message) (state, error) {
+	if g.paused() {
+		return nil, errPaused
+	}
 	if _, ok := g.answeredPlayers[m.player]; ok {
-		return nil, errors.New("already answered")
+		return nil, errAlreadyAnswered
+	}
+	if g.isDrawer(m.player) {
+		return nil, errDrawerGuess
+	}
+	if !g.mode.canGuess(g, m.player) {
+		return nil, errNotYourTeam
 	}
 
 	in, err := m.decodeMessage()
@@ -385,67 +828,32 @@ func (g *Game) handleGuess(m *Message) (state, error) {
 		return nil, err
 	}
 	p := in.(payload[string])
-	ans := strings.TrimSpace(p.Value)
-	ans = strings.ToLower(ans)
+	ans := NormalizeWord(p.Value)
+	m.player.active = true
 
 	if ans == g.currentWord {
 		msg := newMessage(correctGuess, m.player.ID)
 		g.sendToAll(msg)
 
-		if len(g.answeredPlayers) == 0 {
-			g.currentPlayer.Score += 10
-			msg := newMessage(updateScore, scorePayload{
-				Player: g.currentPlayer.ID,
-				Score:  g.currentPlayer.Score,
-			})
-			g.sendToAll(msg)
-		}
-
-		m.player.Score += g.getNextPoint()
-		msg = newMessage(updateScore, scorePayload{
-			Player: m.player.ID,
-			Score:  m.player.Score,
-		})
-		g.sendToAll(msg)
-
-		if g.currentPlayer.Score >= g.targetScore || m.player.Score >= g.targetScore {
-			return &endingState{}, nil
-		}
-
-		g.answeredPlayers[m.player] = struct{}{}
-		finished := true
-		for _, player := range g.players {
-			if player == g.currentPlayer {
-				continue
-			}
-			if _, ok := g.answeredPlayers[player]; !ok {
-				finished = false
-				break
-			}
-		}
-
-		if finished {
-			g.currentPlayer.Score += 1
-			msg := newMessage(updateScore, scorePayload{
-				Player: g.currentPlayer.ID,
-				Score:  g.currentPlayer.Score,
-			})
-			g.sendToAll(msg)
+		return g.mode.correctGuess(g, m.player), nil
+	}
 
-			if g.currentPlayer.Score >= g.targetScore {
-				return &endingState{}, nil
-			}
+	// Muted players can still guess, their wrong guesses are not shown
+	if m.player.isMuted() {
+		return nil, errMuted
+	}
 
-			return &startingState{}, nil
-		}
-	} else {
-		msg := newMessage(guess, messagePayload{
-			Player:  m.player.Name,
-			Message: p.Value,
-		})
-		g.commands = append(g.commands, msg)
-		g.sendToAll(msg)
+	text, err := g.moderate(m.player, p.Value)
+	if err != nil {
+		return nil, err
 	}
 
+	msg := newMessage(guess, messagePayload{
+		Player:  m.player.Name,
+		Message: text,
+	})
+	g.commands = append(g.commands, msg)
+	g.sendToAll(msg)
+
 	return nil, nil
 }
@@ -1,6 +1,10 @@
 package game
 
-import "sync"
+import (
+	"sync"
+
+	"github.com/alperenunal/draw2gather/internal/moderation"
+)
 
 var Hub GameHub
 
@@ -32,3 +36,38 @@ func (h *GameHub) Delete(id string) {
 	defer h.mu.Unlock()
 	delete(h.games, id)
 }
+
+// Sanction applies a sanction to the player in every running game.
+func (h *GameHub) Sanction(s *moderation.Sanction) {
+	h.mu.RLock()
+	games := make([]*Game, 0, len(h.games))
+	for _, g := range h.games {
+		games = append(games, g)
+	}
+	h.mu.RUnlock()
+
+	for _, g := range games {
+		g := g
+		g.post(func() state {
+			return g.applySanction(s)
+		})
+	}
+}
+
+// LiftSanction removes a deleted sanction from players in running games.
+func (h *GameHub) LiftSanction(id string) {
+	h.mu.RLock()
+	games := make([]*Game, 0, len(h.games))
+	for _, g := range h.games {
+		games = append(games, g)
+	}
+	h.mu.RUnlock()
+
+	for _, g := range games {
+		g := g
+		g.post(func() state {
+			g.liftSanction(id)
+			return nil
+		})
+	}
+}
@@ -0,0 +1,66 @@
+package game
+
+import "log/slog"
+
+const (
+	DefaultIdleWarning = 2
+	DefaultIdleLimit   = 3
+)
+
+// updateIdle counts the turns each player did not play in. Players reaching
+// the warning threshold are told, players reaching the limit are removed.
+// Only players that could act count, guessers cannot act before a word is
+// picked and in team mode only the drawer's team guesses.
+func (g *Game) updateIdle() {
+	var idle []*Player
+	for _, p := range g.players {
+		if !g.isDrawer(p) && (!g.wordPicked || !g.mode.canGuess(g, p)) {
+			p.active = false
+			continue
+		}
+
+		if p.active {
+			p.idleTurns = 0
+		} else {
+			p.idleTurns++
+		}
+		p.active = false
+
+		switch {
+		case p.idleTurns >= g.idleLimit:
+			idle = append(idle, p)
+		case p.idleTurns >= g.idleWarning:
+			g.sendToPlayer(p, newMessage(idleWarning, g.idleLimit-p.idleTurns))
+		}
+	}
+
+	if len(idle) == 0 {
+		return
+	}
+
+	// States cannot change while entering a state, players are removed
+	// from the loop once the current transition is done
+	go g.post(func() state {
+		var next state
+		for _, p := range idle {
+			if p.quitted {
+				continue
+			}
+
+			g.logger.Info("Removing idle player", slog.String("player", p.ID))
+			g.sendToPlayer(p, newEmptyMessage(kick))
+			state, err := g.removePlayer(p)
+			if err != nil {
+				g.logger.Error(err.Error())
+			}
+			if state != nil {
+				next = state
+			}
+		}
+		return next
+	})
+}
+
+func (p *Player) isIdle(g *Game) bool {
+	return p.idleTurns >= g.idleWarning
+}
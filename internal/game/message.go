@@ -4,59 +4,117 @@ import (
 	"encoding/json"
 )
 
+const (
+	maxPolygonPoints = 32
+	maxCurvePoints   = 512
+)
+
+// Action codes are part of the wire protocol. They must never be renumbered
+// or reused, new actions get the next free code.
 type action int
 
 const (
-	greet action = iota
+	greet action = 0
 
 	// General actions
-	join
-	quit
-	kick
-	chat
+	join   action = 1
+	quit   action = 2
+	kick   action = 3
+	chat   action = 4
+	report action = 34
+
+	voteKick       action = 35
+	voteKickStatus action = 36
+	idleWarning    action = 41
 
-	newOwner
+	newOwner      action = 5
+	transferOwner action = 44
+	closeGame     action = 45
+	gameClosed    action = 46
+
+	pause       action = 47
+	resume      action = 48
+	pauseStatus action = 49
+
+	play action = 50
 
 	// Waiting state actions
-	start
+	start           action = 6
+	updateSettings  action = 42
+	settingsChanged action = 43
+	addWords        action = 62
+	removeWords     action = 63
+	customWords     action = 64
+	joinTeam        action = 51
+	teamChanged     action = 52
 
 	// Picking state actions
-	pick
+	pick        action = 7
+	skip        action = 37
+	wordChoices action = 60
+	reroll      action = 61
 
 	// Drawing state actions
-	draw
-	erase
-	lineDraw
-	rectDraw
-	rectFill
-	circleDraw
-	circleFill
-	changeColor
-	changePencilSize
-	changeEraserSize
-	clearBoard
-	guess
-
-	correctGuess
-	updateScore
+	draw             action = 8
+	erase            action = 9
+	lineDraw         action = 10
+	rectDraw         action = 11
+	rectFill         action = 12
+	circleDraw       action = 13
+	circleFill       action = 14
+	changeColor      action = 15
+	changePencilSize action = 16
+	changeEraserSize action = 17
+	clearBoard       action = 18
+	guess            action = 19
+	voteSkip         action = 38
+	ellipseDraw      action = 27
+	ellipseFill      action = 28
+	polygonDraw      action = 29
+	polygonFill      action = 30
+	curveDraw        action = 31
+	changeAlphaColor action = 32
+
+	correctGuess    action = 20
+	updateScore     action = 21
+	updateTeamScore action = 53
+	skipped         action = 39
+	voteSkipStatus  action = 40
+
+	// Relay mode actions
+	relayRound    action = 54
+	relaySubmit   action = 55
+	relayProgress action = 56
+	relayReveal   action = 57
+
+	// Co-op mode actions
+	coDrawers  action = 58
+	drawerWord action = 59
 
 	// State actions
-	waiting
-	starting
-	picking
-	drawing
-	ending
+	waiting  action = 22
+	starting action = 23
+	picking  action = 24
+	drawing  action = 25
+	ending   action = 26
+
+	errorReply action = 33
 )
 
 type Message struct {
 	player  *Player `json:"-"`
 	Action  action  `json:"action"`
 	Payload string  `json:"payload,omitempty"`
+	// Drawer of a board action when a turn has several drawers
+	Drawer string `json:"drawer,omitempty"`
 }
 
 type payloadType interface {
-	string | int | []int | [2]string | *Player |
-		pointsPayload | messagePayload | scorePayload | gamePayload
+	string | int | []int | []string | [2]string | *Player |
+		pointsPayload | ellipsePayload | polygonPayload | curvePayload | colorPayload |
+		messagePayload | scorePayload | gamePayload | errorPayload | reportPayload |
+		votePayload | skipPayload | SettingsUpdate | pausePayload | teamPayload | teamScorePayload |
+		relayRoundPayload | relayProgressPayload | relayChain | []wordChoice
 }
 
 type payload[T payloadType] struct {
@@ -73,6 +131,63 @@ type pointsPayload struct {
 	End   point `json:"end"`
 }
 
+type ellipsePayload struct {
+	Center  point `json:"center"`
+	RadiusX int   `json:"radius_x"`
+	RadiusY int   `json:"radius_y"`
+}
+
+func (p *ellipsePayload) validate() error {
+	if p.RadiusX <= 0 || p.RadiusY <= 0 {
+		return errInvalidPayload
+	}
+	return nil
+}
+
+// polygonPayload is a closed shape, three points draw a triangle.
+type polygonPayload struct {
+	Points []point `json:"points"`
+}
+
+func (p *polygonPayload) validate() error {
+	if len(p.Points) < 3 || len(p.Points) > maxPolygonPoints {
+		return errInvalidPayload
+	}
+	return nil
+}
+
+// curvePayload is a polyline that clients smooth with a Catmull-Rom spline,
+// tension 0 draws straight segments.
+type curvePayload struct {
+	Points  []point `json:"points"`
+	Tension float64 `json:"tension"`
+}
+
+func (p *curvePayload) validate() error {
+	if len(p.Points) < 2 || len(p.Points) > maxCurvePoints {
+		return errInvalidPayload
+	}
+	if p.Tension < 0 || p.Tension > 1 {
+		return errInvalidPayload
+	}
+	return nil
+}
+
+type colorPayload struct {
+	Color string  `json:"color"`
+	Alpha float64 `json:"alpha"`
+}
+
+func (p *colorPayload) validate() error {
+	if p.Color == "" {
+		return errInvalidPayload
+	}
+	if p.Alpha < 0 || p.Alpha > 1 {
+		return errInvalidPayload
+	}
+	return nil
+}
+
 type messagePayload struct {
 	Player  string `json:"player"`
 	Message string `json:"message"`
@@ -83,13 +198,54 @@ type scorePayload struct {
 	Score  int    `json:"score"`
 }
 
+type reportPayload struct {
+	Player string `json:"player"`
+	Reason string `json:"reason"`
+}
+
+type votePayload struct {
+	Target   string `json:"target"`
+	Votes    int    `json:"votes"`
+	Required int    `json:"required"`
+	Ended    bool   `json:"ended"`
+	Passed   bool   `json:"passed"`
+}
+
+type skipPayload struct {
+	Player string `json:"player"`
+	// Empty if the turn is skipped before a word is picked
+	Word string `json:"word,omitempty"`
+}
+
+type pausePayload struct {
+	Paused bool `json:"paused"`
+	// Milliseconds left in the current state
+	Remaining int64 `json:"remaining"`
+	// Milliseconds a pause can last
+	MaxPause int64 `json:"max_pause"`
+}
+
+type errorPayload struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+	Action  action `json:"action"`
+}
+
 type gamePayload struct {
 	State         string             `json:"state"`
 	Player        string             `json:"player"`
 	Owner         string             `json:"owner"`
 	CurrentPlayer string             `json:"current_player"`
 	Players       map[string]*Player `json:"players"`
+	Spectators    map[string]*Player `json:"spectators"`
 	Commands      []*Message         `json:"commands"`
+	Settings      SettingsUpdate     `json:"settings"`
+	Drawers       []string           `json:"drawers,omitempty"`
+	TeamScores    []int              `json:"team_scores,omitempty"`
+	CustomWords   []string           `json:"custom_words,omitempty"`
+	Paused        bool               `json:"paused"`
+	Protocol      int                `json:"protocol"`
+	Capabilities  []string           `json:"capabilities"`
 }
 
 func newEmptyMessage(act action) *Message {
@@ -110,10 +266,10 @@ func newMessage[T payloadType](act action, val T) *Message {
 
 func (m *Message) decodeMessage() (any, error) {
 	switch m.Action {
-	case quit, start, clearBoard:
+	case quit, start, clearBoard, skip, voteSkip, closeGame, pause, resume, play, reroll:
 		return nil, nil
 
-	case kick, chat, pick, changeColor, guess:
+	case kick, voteKick, transferOwner, chat, pick, changeColor, guess, relaySubmit:
 		var p payload[string]
 		err := json.Unmarshal([]byte(m.Payload), &p)
 		if err != nil {
@@ -121,6 +277,38 @@ func (m *Message) decodeMessage() (any, error) {
 		}
 		return p, nil
 
+	case joinTeam:
+		var p payload[int]
+		err := json.Unmarshal([]byte(m.Payload), &p)
+		if err != nil {
+			return nil, err
+		}
+		return p, nil
+
+	case addWords, removeWords:
+		var p payload[[]string]
+		err := json.Unmarshal([]byte(m.Payload), &p)
+		if err != nil {
+			return nil, err
+		}
+		return p, nil
+
+	case updateSettings:
+		var p payload[SettingsUpdate]
+		err := json.Unmarshal([]byte(m.Payload), &p)
+		if err != nil {
+			return nil, err
+		}
+		return p, nil
+
+	case report:
+		var p payload[reportPayload]
+		err := json.Unmarshal([]byte(m.Payload), &p)
+		if err != nil {
+			return nil, err
+		}
+		return p, nil
+
 	case changePencilSize, changeEraserSize:
 		var p payload[int]
 		err := json.Unmarshal([]byte(m.Payload), &p)
@@ -145,6 +333,50 @@ func (m *Message) decodeMessage() (any, error) {
 		}
 		return p, nil
 
+	case ellipseDraw, ellipseFill:
+		var p payload[ellipsePayload]
+		err := json.Unmarshal([]byte(m.Payload), &p)
+		if err != nil {
+			return nil, err
+		}
+		if err := p.Value.validate(); err != nil {
+			return nil, err
+		}
+		return p, nil
+
+	case polygonDraw, polygonFill:
+		var p payload[polygonPayload]
+		err := json.Unmarshal([]byte(m.Payload), &p)
+		if err != nil {
+			return nil, err
+		}
+		if err := p.Value.validate(); err != nil {
+			return nil, err
+		}
+		return p, nil
+
+	case curveDraw:
+		var p payload[curvePayload]
+		err := json.Unmarshal([]byte(m.Payload), &p)
+		if err != nil {
+			return nil, err
+		}
+		if err := p.Value.validate(); err != nil {
+			return nil, err
+		}
+		return p, nil
+
+	case changeAlphaColor:
+		var p payload[colorPayload]
+		err := json.Unmarshal([]byte(m.Payload), &p)
+		if err != nil {
+			return nil, err
+		}
+		if err := p.Value.validate(); err != nil {
+			return nil, err
+		}
+		return p, nil
+
 	default:
 		return nil, errInvalidAction
 	}
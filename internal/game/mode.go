@@ -0,0 +1,200 @@
+package game
+
+import (
+	"slices"
+)
+
+const (
+	ClassicMode = "classic"
+	// RelayMode alternates writing and drawing, every player starts a chain
+	// that is passed on to the next player each round.
+	RelayMode = "relay"
+	// CoopMode has two players draw the same word on one canvas.
+	CoopMode = "coop"
+)
+
+// gameMode is a rule set. The lobby, ending and closing states are shared,
+// a mode supplies the states in between.
+type gameMode interface {
+	name() string
+	// minPlayers is the number of players a game needs to go on
+	minPlayers() int
+	// start checks the players and returns the first state of a game
+	start(g *Game) (state, error)
+	// pickDrawers returns the drawers of the next turn, the first one leads
+	// the turn. It returns nil if there are not enough players to draw.
+	pickDrawers(g *Game) []*Player
+	// canGuess reports whether the player can score in the current turn
+	canGuess(g *Game, p *Player) bool
+	// correctGuess scores a correct guess and returns the next state
+	correctGuess(g *Game, p *Player) state
+	// reachedTarget reports whether the player ends the game
+	reachedTarget(g *Game, p *Player) bool
+}
+
+var modes = map[string]gameMode{
+	ClassicMode: classicMode{},
+	RelayMode:   relayMode{},
+	CoopMode:    coopMode{},
+}
+
+func ValidMode(mode string) bool {
+	_, ok := modes[mode]
+	return ok
+}
+
+// classicMode takes turns drawing a word the others guess, optionally in
+// teams.
+type classicMode struct{}
+
+func (classicMode) name() string {
+	return ClassicMode
+}
+
+func (classicMode) minPlayers() int {
+	return 2
+}
+
+func (classicMode) start(g *Game) (state, error) {
+	if g.teams > 0 && !g.teamsReady() {
+		return nil, errNotEnoughPlayers
+	}
+	if g.words.size() < g.wordChoices {
+		return nil, errNotEnoughWords
+	}
+	return &startingState{}, nil
+}
+
+func (m classicMode) pickDrawers(g *Game) []*Player {
+	p := m.pickPlayer(g)
+	if p == nil {
+		return nil
+	}
+	return []*Player{p}
+}
+
+// pickPlayer moves the next player that is not idle to the end of the
+// queue and returns them. If everyone is idle the next player in the queue is
+// picked. In team mode the player comes from the next team if possible. It
+// returns nil if the queue is empty.
+func (classicMode) pickPlayer(g *Game) *Player {
+	team := 0
+	if g.teams > 0 {
+		team = g.nextTeam()
+	}
+	inTeam := func(p *Player) bool {
+		return team == 0 || p.Team == team
+	}
+
+	for _, ok := range []func(*Player) bool{
+		func(p *Player) bool { return inTeam(p) && !p.isIdle(g) },
+		inTeam,
+		func(p *Player) bool { return true },
+	} {
+		i := slices.IndexFunc(g.playerQueue, ok)
+		if i < 0 {
+			continue
+		}
+
+		p := g.playerQueue[i]
+		g.playerQueue = append(slices.Delete(g.playerQueue, i, i+1), p)
+		g.lastTeam = p.Team
+		return p
+	}
+	return nil
+}
+
+func (classicMode) canGuess(g *Game, p *Player) bool {
+	if g.isDrawer(p) {
+		return false
+	}
+	return g.teams == 0 || p.Team == g.currentPlayer.Team
+}
+
+func (m classicMode) correctGuess(g *Game, p *Player) state {
+	if len(g.answeredPlayers) == 0 {
+		for _, drawer := range g.drawers {
+			g.addScore(drawer, g.points(10))
+		}
+	}
+	g.addScore(p, g.points(g.getNextPoint()))
+
+	if m.reachedTarget(g, p) || slices.ContainsFunc(g.drawers, func(drawer *Player) bool {
+		return m.reachedTarget(g, drawer)
+	}) {
+		return &endingState{}
+	}
+
+	g.answeredPlayers[p] = struct{}{}
+	for _, player := range g.players {
+		if !m.canGuess(g, player) {
+			continue
+		}
+		if _, ok := g.answeredPlayers[player]; !ok {
+			return nil
+		}
+	}
+
+	ended := false
+	for _, drawer := range g.drawers {
+		g.addScore(drawer, g.points(1))
+		ended = ended || m.reachedTarget(g, drawer)
+	}
+	if ended {
+		return &endingState{}
+	}
+	return &startingState{}
+}
+
+// reachedTarget reports whether the player or, in team mode, their team
+// has won.
+func (classicMode) reachedTarget(g *Game, p *Player) bool {
+	if g.teams > 0 {
+		return g.teamScores[p.Team-1] >= g.targetScore
+	}
+	return p.Score >= g.targetScore
+}
+
+// relayMode has no turns or scores, it only replaces how a game starts.
+// The classic rules it embeds are never reached.
+type relayMode struct {
+	classicMode
+}
+
+func (relayMode) name() string {
+	return RelayMode
+}
+
+func (relayMode) start(g *Game) (state, error) {
+	g.relay = newRelay(g.playerQueue)
+	return &relayRoundState{}, nil
+}
+
+// coopMode plays the classic rules with two drawers sharing each turn.
+type coopMode struct {
+	classicMode
+}
+
+func (coopMode) name() string {
+	return CoopMode
+}
+
+// minPlayers is two drawers and at least one player to guess.
+func (coopMode) minPlayers() int {
+	return 3
+}
+
+func (coopMode) start(g *Game) (state, error) {
+	if g.words.size() < g.wordChoices {
+		return nil, errNotEnoughWords
+	}
+	return &startingState{}, nil
+}
+
+func (m coopMode) pickDrawers(g *Game) []*Player {
+	first, second := m.pickPlayer(g), m.pickPlayer(g)
+	if first == nil || second == nil || first == second {
+		return nil
+	}
+	return []*Player{first, second}
+}
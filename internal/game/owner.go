@@ -0,0 +1,74 @@
+package game
+
+import (
+	"context"
+	"log/slog"
+
+	"cloud.google.com/go/firestore"
+)
+
+// successor is the player that has been in the game the longest.
+func (g *Game) successor() *Player {
+	var next *Player
+	for _, p := range g.players {
+		if next == nil || p.joinedAt.Before(next.joinedAt) ||
+			(p.joinedAt.Equal(next.joinedAt) && p.ID < next.ID) {
+			next = p
+		}
+	}
+	return next
+}
+
+func (g *Game) setOwner(p *Player) {
+	g.owner = p.ID
+	g.db.Collection("games").Doc(g.id).Update(context.Background(), []firestore.Update{
+		{Path: "owner", Value: p.ID},
+	})
+	g.logger.Info("Owner changed", slog.String("player", p.ID))
+
+	msg := newMessage(newOwner, p.ID)
+	g.sendToAll(msg)
+}
+
+func (g *Game) handleTransferOwner(m *Message) error {
+	if m.player.ID != g.owner {
+		return errNotOwner
+	}
+
+	in, err := m.decodeMessage()
+	if err != nil {
+		return err
+	}
+	p := in.(payload[string])
+
+	player, ok := g.players[p.Value]
+	if !ok {
+		return errPlayerNotFound
+	}
+	if player == m.player {
+		return errInvalidTarget
+	}
+
+	g.setOwner(player)
+	return nil
+}
+
+func (g *Game) handleCloseGame(m *Message) (state, error) {
+	if m.player.ID != g.owner {
+		return nil, errNotOwner
+	}
+
+	g.logger.Info("Game closed by owner", slog.String("player", m.player.ID))
+	g.sendToAll(newEmptyMessage(gameClosed))
+
+	for _, p := range g.players {
+		g.sessions.Remove(p.ctx, "game_id")
+		g.sessions.Commit(p.ctx)
+
+		delete(g.players, p.ID)
+		g.disconnect(p)
+	}
+	g.playerQueue = []*Player{}
+
+	return &closingState{}, nil
+}
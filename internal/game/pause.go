@@ -0,0 +1,82 @@
+package game
+
+import (
+	"log/slog"
+	"time"
+)
+
+const maxPause = 2 * time.Minute
+
+// pausableState is a state with a timer that the owner can pause.
+type pausableState interface {
+	state
+	phaseTimer() *phaseTimer
+}
+
+func (g *Game) handlePause(m *Message) error {
+	if m.player.ID != g.owner {
+		return errNotOwner
+	}
+	if g.pauseTimer != nil {
+		return errPaused
+	}
+	s, ok := g.state.(pausableState)
+	if !ok {
+		return errWrongState
+	}
+
+	s.phaseTimer().Pause()
+
+	var timer *time.Timer
+	timer = time.AfterFunc(maxPause, func() {
+		g.post(func() state {
+			if g.pauseTimer == timer {
+				g.logger.Info("Pause expired")
+				g.resume()
+			}
+			return nil
+		})
+	})
+	g.pauseTimer = timer
+
+	g.logger.Info("Game paused", slog.String("player", m.player.ID))
+	g.sendPauseStatus()
+	return nil
+}
+
+func (g *Game) handleResume(m *Message) error {
+	if m.player.ID != g.owner {
+		return errNotOwner
+	}
+	if g.pauseTimer == nil {
+		return errNotPaused
+	}
+
+	g.logger.Info("Game resumed", slog.String("player", m.player.ID))
+	g.resume()
+	return nil
+}
+
+func (g *Game) resume() {
+	g.pauseTimer.Stop()
+	g.pauseTimer = nil
+	if s, ok := g.state.(pausableState); ok {
+		s.phaseTimer().Resume()
+	}
+	g.sendPauseStatus()
+}
+
+func (g *Game) paused() bool {
+	return g.pauseTimer != nil
+}
+
+func (g *Game) sendPauseStatus() {
+	p := pausePayload{
+		Paused:   g.paused(),
+		MaxPause: maxPause.Milliseconds(),
+	}
+	if s, ok := g.state.(pausableState); ok {
+		p.Remaining = s.phaseTimer().Remaining().Milliseconds()
+	}
+	g.sendToAll(newMessage(pauseStatus, p))
+}
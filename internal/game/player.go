@@ -2,7 +2,11 @@ package game
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
+	"time"
 
+	"github.com/alperenunal/draw2gather/internal/moderation"
 	"github.com/gorilla/websocket"
 )
 
@@ -10,49 +14,111 @@ type Player struct {
 	ID    string `json:"id"`
 	Name  string `json:"name"`
 	Score int    `json:"score"`
+	// Spectators see the game but cannot guess or draw
+	Spectator bool `json:"spectator,omitempty"`
+	// Team number starting from 1, 0 without teams
+	Team int `json:"team,omitempty"`
 
-	quitted bool            `json:"-"`
-	ctx     context.Context `json:"-"`
-	conn    *websocket.Conn `json:"-"`
+	quitted  bool            `json:"-"`
+	ctx      context.Context `json:"-"`
+	conn     *websocket.Conn `json:"-"`
+	encoding Encoding        `json:"-"`
+	version  int             `json:"-"`
+	limiter  *rateLimiter    `json:"-"`
+
+	joinedAt time.Time            `json:"-"`
+	mute     *moderation.Sanction `json:"-"`
+
+	// Words drawn in the player's last games
+	recentWords *RecentWords `json:"-"`
+
+	// Whether the player guessed or drew this turn
+	active    bool `json:"-"`
+	idleTurns int  `json:"-"`
+
+	// Whether a spectator joins as a player at the next waiting state
+	wantsToPlay bool `json:"-"`
 
 	game *Game         `json:"-"`
 	ch   chan *Message `json:"-"`
 }
 
-func NewPlayer(id, name string, ctx context.Context, conn *websocket.Conn, game *Game) *Player {
+func NewPlayer(id, name string, ctx context.Context, conn *websocket.Conn, encoding Encoding, version int, game *Game) *Player {
 	return &Player{
-		ID:      id,
-		Name:    name,
-		Score:   0,
-		quitted: false,
-		ctx:     ctx,
-		conn:    conn,
-		ch:      make(chan *Message, 256),
-		game:    game,
+		ID:       id,
+		Name:     name,
+		Score:    0,
+		quitted:  false,
+		ctx:      ctx,
+		conn:     conn,
+		encoding: encoding,
+		version:  version,
+		limiter:  newRateLimiter(),
+		ch:       make(chan *Message, 256),
+		game:     game,
 	}
 }
 
+// ApplySanctions restricts the player with sanctions loaded before joining.
+func (p *Player) ApplySanctions(sanctions []*moderation.Sanction) {
+	p.mute = moderation.Find(sanctions, moderation.Mute)
+}
+
+func (p *Player) isMuted() bool {
+	return p.mute != nil && p.mute.Active(time.Now())
+}
+
 func (p *Player) ReadPump() {
 	defer func() {
-		if !p.quitted {
-			p.game.ch <- &Message{
-				player: p,
-				Action: quit,
-			}
-		}
+		// The game ignores the quit if it removed the player already
+		p.game.send(&Message{
+			player: p,
+			Action: quit,
+		})
 		p.conn.Close()
 	}()
 
 	for {
-		var msg Message
-		err := p.conn.ReadJSON(&msg)
-		if err != nil {
+		msg, err := p.readMessage()
+		if errors.Is(err, errReadFailed) {
 			// Connection closed
 			return
 		}
+		if err == nil && !p.supports(msg.Action) {
+			// Actions newer than the negotiated version are not accepted
+			err = errInvalidAction
+		}
+		if err != nil {
+			// Malformed frames count towards the rate limit like any other
+			// message and are answered with an error
+			if !errors.As(err, new(*gameError)) {
+				err = errInvalidPayload
+			}
+			if msg == nil {
+				msg = &Message{}
+			}
+		}
+
+		if ok, strikes := p.limiter.allow(msg.Action); !ok {
+			// Only the first strike and the last one are reported, the
+			// rest are dropped here so floods never reach the game loop
+			if strikes == 1 || strikes == maxStrikes {
+				p.game.post(func() state {
+					return p.game.handleFlood(p, msg.Action, strikes)
+				})
+			}
+			continue
+		}
 
 		msg.player = p
-		p.game.ch <- &msg
+		if err != nil {
+			p.game.post(func() state {
+				p.game.reject(msg, err)
+				return nil
+			})
+			continue
+		}
+		p.game.send(msg)
 	}
 }
 
@@ -63,9 +129,46 @@ func (p *Player) WritePump() {
 	}()
 
 	for msg := range p.ch {
-		err := p.conn.WriteJSON(msg)
+		// Older clients do not know actions added in later versions
+		if !p.supports(msg.Action) {
+			continue
+		}
+
+		err := p.writeMessage(msg)
 		if err != nil {
 			return
 		}
 	}
 }
+
+func (p *Player) readMessage() (*Message, error) {
+	typ, data, err := p.conn.ReadMessage()
+	if err != nil {
+		return nil, errReadFailed
+	}
+
+	if typ == websocket.BinaryMessage {
+		if !p.hasCapability("binary") {
+			return nil, errInvalidAction
+		}
+		return decodeBinary(data)
+	}
+
+	var msg Message
+	err = json.Unmarshal(data, &msg)
+	if err != nil {
+		return nil, err
+	}
+	return &msg, nil
+}
+
+func (p *Player) writeMessage(msg *Message) error {
+	// Binary frames have no room for the drawer, attributed actions are JSON
+	if p.hasCapability("binary") && isBoardAction(msg.Action) && msg.Drawer == "" {
+		data, err := encodeBinary(msg)
+		if err == nil {
+			return p.conn.WriteMessage(websocket.BinaryMessage, data)
+		}
+	}
+	return p.conn.WriteJSON(msg)
+}
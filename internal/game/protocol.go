@@ -0,0 +1,128 @@
+package game
+
+import (
+	"errors"
+	"slices"
+)
+
+const (
+	ProtocolVersion    = 16
+	MinProtocolVersion = 1
+)
+
+var ErrUnsupportedVersion = errors.New("unsupported protocol version")
+
+// actionVersions holds the protocol version that introduced each action,
+// actions missing here are part of version 1.
+var actionVersions = map[action]int{
+	ellipseDraw:      2,
+	ellipseFill:      2,
+	polygonDraw:      2,
+	polygonFill:      2,
+	curveDraw:        2,
+	changeAlphaColor: 2,
+	errorReply:       3,
+	report:           4,
+	voteKick:         5,
+	voteKickStatus:   5,
+	skip:             6,
+	voteSkip:         6,
+	skipped:          6,
+	voteSkipStatus:   6,
+	idleWarning:      7,
+	updateSettings:   8,
+	settingsChanged:  8,
+	transferOwner:    9,
+	closeGame:        9,
+	gameClosed:       9,
+	pause:            10,
+	resume:           10,
+	pauseStatus:      10,
+	play:             11,
+	joinTeam:         12,
+	teamChanged:      12,
+	updateTeamScore:  12,
+	relayRound:       13,
+	relaySubmit:      13,
+	relayProgress:    13,
+	relayReveal:      13,
+	coDrawers:        14,
+	drawerWord:       14,
+	wordChoices:      15,
+	reroll:           15,
+	addWords:         16,
+	removeWords:      16,
+	customWords:      16,
+}
+
+type capability struct {
+	name    string
+	version int
+}
+
+var capabilities = []capability{
+	{name: "shapes", version: 2},
+	{name: "binary", version: 2},
+	{name: "errors", version: 3},
+	{name: "reports", version: 4},
+	{name: "vote_kick", version: 5},
+	{name: "skip", version: 6},
+	{name: "idle", version: 7},
+	{name: "lobby_settings", version: 8},
+	{name: "owner_controls", version: 9},
+	{name: "pause", version: 10},
+	{name: "spectators", version: 11},
+	{name: "teams", version: 12},
+	{name: "relay", version: 13},
+	{name: "coop", version: 14},
+	{name: "word_choices", version: 15},
+	{name: "custom_words", version: 16},
+}
+
+func (a action) version() int {
+	if v, ok := actionVersions[a]; ok {
+		return v
+	}
+	return MinProtocolVersion
+}
+
+// NegotiateVersion returns the version used with a client that asked for
+// requested. Clients that do not send a version are treated as version 1 and
+// newer clients are downgraded to the server version.
+func NegotiateVersion(requested int) (int, error) {
+	if requested == 0 {
+		return MinProtocolVersion, nil
+	}
+	if requested < MinProtocolVersion {
+		return 0, ErrUnsupportedVersion
+	}
+	return min(requested, ProtocolVersion), nil
+}
+
+func (p *Player) supports(a action) bool {
+	return a.version() <= p.version
+}
+
+func (p *Player) capabilities() []string {
+	caps := []string{}
+	for _, c := range capabilities {
+		if p.has(c) {
+			caps = append(caps, c.name)
+		}
+	}
+	return caps
+}
+
+func (p *Player) has(c capability) bool {
+	if c.version > p.version {
+		return false
+	}
+	return c.name != "binary" || p.encoding == BinaryEncoding
+}
+
+func (p *Player) hasCapability(name string) bool {
+	i := slices.IndexFunc(capabilities, func(c capability) bool {
+		return c.name == name
+	})
+	return i >= 0 && p.has(capabilities[i])
+}
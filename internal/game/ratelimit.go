@@ -0,0 +1,95 @@
+package game
+
+import "time"
+
+const (
+	// Players dropping this many messages without a quiet period are kicked
+	maxStrikes  = 20
+	strikeReset = 10 * time.Second
+)
+
+type category int
+
+const (
+	generalCategory category = iota
+	chatCategory
+	guessCategory
+	drawCategory
+)
+
+type bucketLimit struct {
+	rate  float64 // tokens per second
+	burst float64
+}
+
+var limits = map[category]bucketLimit{
+	generalCategory: {rate: 2, burst: 5},
+	chatCategory:    {rate: 1, burst: 5},
+	guessCategory:   {rate: 2, burst: 5},
+	drawCategory:    {rate: 60, burst: 120},
+}
+
+func categoryOf(a action) category {
+	switch {
+	case a == chat:
+		return chatCategory
+	case a == guess:
+		return guessCategory
+	case isBoardAction(a):
+		return drawCategory
+	default:
+		return generalCategory
+	}
+}
+
+type tokenBucket struct {
+	limit  bucketLimit
+	tokens float64
+	last   time.Time
+}
+
+func (b *tokenBucket) take(now time.Time) bool {
+	b.tokens = min(b.limit.burst, b.tokens+now.Sub(b.last).Seconds()*b.limit.rate)
+	b.last = now
+	if b.tokens < 1 {
+		return false
+	}
+	b.tokens--
+	return true
+}
+
+// rateLimiter is owned by the read pump of a single player.
+type rateLimiter struct {
+	buckets    map[category]*tokenBucket
+	strikes    int
+	lastStrike time.Time
+}
+
+func newRateLimiter() *rateLimiter {
+	now := time.Now()
+	buckets := make(map[category]*tokenBucket, len(limits))
+	for c, l := range limits {
+		buckets[c] = &tokenBucket{limit: l, tokens: l.burst, last: now}
+	}
+	return &rateLimiter{buckets: buckets}
+}
+
+// allow reports whether a message with the given action may be forwarded to
+// the game. Rejected messages are counted as strikes, the count is returned.
+func (l *rateLimiter) allow(a action) (bool, int) {
+	if a == quit {
+		return true, 0
+	}
+
+	now := time.Now()
+	if l.buckets[categoryOf(a)].take(now) {
+		return true, 0
+	}
+
+	if now.Sub(l.lastStrike) > strikeReset {
+		l.strikes = 0
+	}
+	l.strikes++
+	l.lastStrike = now
+	return false, l.strikes
+}
@@ -0,0 +1,131 @@
+package game
+
+import (
+	"context"
+	"log/slog"
+
+	"cloud.google.com/go/firestore"
+)
+
+// Games a drawn word is remembered for
+const recentGames = 5
+
+// RecentWords is stored per account or, for guests, per session. Words map
+// to the number of the game they were last seen in.
+type RecentWords struct {
+	Games int            `firestore:"games"`
+	Words map[string]int `firestore:"words"`
+}
+
+func (r *RecentWords) seen(word string) bool {
+	if r == nil {
+		return false
+	}
+	game, ok := r.Words[word]
+	return ok && game > r.Games-recentGames
+}
+
+// add records the words of a finished game and forgets words older than
+// the last games.
+func (r *RecentWords) add(words []string) {
+	if r.Words == nil {
+		r.Words = make(map[string]int)
+	}
+
+	r.Games++
+	for word, game := range r.Words {
+		if game <= r.Games-recentGames {
+			delete(r.Words, word)
+		}
+	}
+	for _, word := range words {
+		r.Words[word] = r.Games
+	}
+}
+
+func recentWordsRef(db *firestore.Client, playerID, userID string) *firestore.DocumentRef {
+	if userID != "" {
+		return db.Collection("recent_words").Doc("account:" + userID)
+	}
+	return db.Collection("recent_words").Doc("session:" + playerID)
+}
+
+// LoadRecentWords returns the words the player saw in their last games.
+func LoadRecentWords(ctx context.Context, db *firestore.Client, playerID, userID string) (*RecentWords, error) {
+	recent := &RecentWords{}
+
+	// Get returns the snapshot of a missing document along with its error
+	doc, err := recentWordsRef(db, playerID, userID).Get(ctx)
+	if err != nil && doc == nil {
+		return nil, err
+	}
+	if !doc.Exists() {
+		return recent, nil
+	}
+
+	err = doc.DataTo(recent)
+	if err != nil {
+		return nil, err
+	}
+	return recent, nil
+}
+
+// SetRecentWords keeps words the player saw in earlier games out of their
+// word choices where possible, it must be called before the player is
+// registered.
+func (p *Player) SetRecentWords(recent *RecentWords) {
+	p.recentWords = recent
+}
+
+// recentlySeen reports whether a player saw the word in their last games,
+// games with a fixed seed ignore it to stay reproducible.
+func (g *Game) recentlySeen(word string) bool {
+	if g.fixedSeed {
+		return false
+	}
+	for _, p := range g.players {
+		if p.recentWords.seen(word) {
+			return true
+		}
+	}
+	return false
+}
+
+// recordRecentWords stores the words drawn in the finished game for every
+// player that is still there.
+func (g *Game) recordRecentWords() {
+	if len(g.drawnWords) == 0 {
+		return
+	}
+
+	words := g.drawnWords
+	for _, p := range g.players {
+		if p.recentWords == nil {
+			p.recentWords = &RecentWords{}
+		}
+		p.recentWords.add(words)
+
+		ref := recentWordsRef(g.db, p.ID, g.sessions.GetString(p.ctx, "user_id"))
+		go func() {
+			err := g.db.RunTransaction(context.Background(), func(ctx context.Context, tx *firestore.Transaction) error {
+				var recent RecentWords
+				doc, err := tx.Get(ref)
+				if err != nil && doc == nil {
+					return err
+				}
+				if doc.Exists() {
+					err = doc.DataTo(&recent)
+					if err != nil {
+						return err
+					}
+				}
+
+				recent.add(words)
+				return tx.Set(ref, &recent)
+			})
+			if err != nil {
+				g.logger.Error("Failed to record recent words", slog.String("error", err.Error()))
+			}
+		}()
+	}
+}
@@ -0,0 +1,284 @@
+package game
+
+import (
+	"log/slog"
+	"slices"
+	"time"
+)
+
+type relayEntry struct {
+	Player string `json:"player"`
+	// Prompt or description, empty for drawings
+	Text     string     `json:"text,omitempty"`
+	Commands []*Message `json:"commands,omitempty"`
+}
+
+type relayChain struct {
+	Owner   string        `json:"owner"`
+	Entries []*relayEntry `json:"entries"`
+}
+
+type relayRoundPayload struct {
+	Round  int    `json:"round"`
+	Rounds int    `json:"rounds"`
+	Kind   string `json:"kind"`
+	// What the previous player wrote or drew
+	Prompt   string     `json:"prompt,omitempty"`
+	Commands []*Message `json:"commands,omitempty"`
+}
+
+type relayProgressPayload struct {
+	Done  int `json:"done"`
+	Total int `json:"total"`
+}
+
+// relay holds the chains of a relay game, chain i is started by player i
+// and handled by player (i + round) in each round.
+type relay struct {
+	players []*Player
+	chains  []*relayChain
+}
+
+func newRelay(players []*Player) *relay {
+	r := &relay{
+		players: slices.Clone(players),
+		chains:  make([]*relayChain, len(players)),
+	}
+	for i, p := range players {
+		r.chains[i] = &relayChain{Owner: p.ID, Entries: []*relayEntry{}}
+	}
+	return r
+}
+
+func (r *relay) chain(player, round int) *relayChain {
+	n := len(r.players)
+	return r.chains[(player-round%n+n)%n]
+}
+
+type relayRoundState struct {
+	round     int
+	timer     *phaseTimer
+	entries   map[*Player]*relayEntry
+	submitted map[*Player]struct{}
+}
+
+func (s *relayRoundState) kind() string {
+	switch {
+	case s.round == 0:
+		return "write"
+	case s.round%2 == 1:
+		return "draw"
+	default:
+		return "describe"
+	}
+}
+
+func (s *relayRoundState) next(g *Game) state {
+	if s.round+1 < len(g.relay.players) {
+		return &relayRoundState{round: s.round + 1}
+	}
+	return &relayRevealState{}
+}
+
+func (s *relayRoundState) Enter(g *Game) {
+	g.logger.Info("Entering relay round", slog.Int("round", s.round), slog.String("kind", s.kind()))
+
+	s.entries = make(map[*Player]*relayEntry)
+	s.submitted = make(map[*Player]struct{})
+	for i, p := range g.relay.players {
+		chain := g.relay.chain(i, s.round)
+		// Players that left keep their place so chains stay in order
+		entry := &relayEntry{Player: p.ID}
+		chain.Entries = append(chain.Entries, entry)
+		if g.players[p.ID] != p {
+			continue
+		}
+		s.entries[p] = entry
+
+		payload := relayRoundPayload{
+			Round:  s.round,
+			Rounds: len(g.relay.players),
+			Kind:   s.kind(),
+		}
+		if s.round > 0 {
+			prev := chain.Entries[len(chain.Entries)-2]
+			payload.Prompt = prev.Text
+			payload.Commands = prev.Commands
+		}
+		g.sendToPlayer(p, newMessage(relayRound, payload))
+	}
+
+	d := 45 * time.Second
+	if s.kind() == "draw" {
+		d = 90 * time.Second
+	}
+	next := s.next(g)
+	s.timer = newPhaseTimer(d, func() {
+		g.expire(s, next)
+	})
+}
+
+func (s *relayRoundState) phaseTimer() *phaseTimer {
+	return s.timer
+}
+
+func (s *relayRoundState) Exit(g *Game) {
+	s.timer.Stop()
+	g.logger.Info("Exiting relay round", slog.Int("round", s.round))
+}
+
+// finished reports whether every player still in the game has submitted.
+func (s *relayRoundState) finished(g *Game) bool {
+	for p := range s.entries {
+		if _, ok := s.submitted[p]; !ok && g.players[p.ID] == p {
+			return false
+		}
+	}
+	return true
+}
+
+// afterLeave ends the round early if the player that left was the last one
+// everyone was waiting for.
+func (s *relayRoundState) afterLeave(g *Game, state state) state {
+	if state == nil && s.finished(g) {
+		return s.next(g)
+	}
+	return state
+}
+
+func (s *relayRoundState) HandleMessage(g *Game, m *Message) state {
+	if state, ok := g.handleCommon(m); ok {
+		return s.afterLeave(g, state)
+	}
+
+	switch m.Action {
+	case draw, erase, lineDraw, rectDraw, rectFill, circleDraw, circleFill,
+		ellipseDraw, ellipseFill, polygonDraw, polygonFill, curveDraw,
+		changeColor, changeAlphaColor, changePencilSize, changeEraserSize, clearBoard:
+		err := g.handleRelayBoardAction(s, m)
+		if err != nil {
+			g.reject(m, err)
+		}
+		return nil
+	case relaySubmit:
+		state, err := g.handleRelaySubmit(s, m)
+		if err != nil {
+			g.reject(m, err)
+		}
+		return state
+	default:
+		g.reject(m, errWrongState)
+		return nil
+	}
+}
+
+// handleRelayBoardAction draws on the player's own canvas, drawings are only
+// shown to the next player and in the reveal.
+func (g *Game) handleRelayBoardAction(s *relayRoundState, m *Message) error {
+	if s.kind() != "draw" {
+		return errWrongState
+	}
+	entry, ok := s.entries[m.player]
+	if !ok {
+		return errNotYourTurn
+	}
+	if _, ok := s.submitted[m.player]; ok {
+		return errAlreadyAnswered
+	}
+	if g.paused() {
+		return errPaused
+	}
+	if _, err := m.decodeMessage(); err != nil {
+		return err
+	}
+
+	// Drawers are only set by the server
+	m.Drawer = ""
+	entry.Commands = append(entry.Commands, m)
+	return nil
+}
+
+func (g *Game) handleRelaySubmit(s *relayRoundState, m *Message) (state, error) {
+	entry, ok := s.entries[m.player]
+	if !ok {
+		return nil, errNotYourTurn
+	}
+	if _, ok := s.submitted[m.player]; ok {
+		return nil, errAlreadyAnswered
+	}
+	if g.paused() {
+		return nil, errPaused
+	}
+
+	// Drawings are submitted as they are, the payload is only read for text
+	if s.kind() != "draw" {
+		if m.player.isMuted() {
+			return nil, errMuted
+		}
+
+		in, err := m.decodeMessage()
+		if err != nil {
+			return nil, err
+		}
+		p := in.(payload[string])
+
+		text, err := g.moderate(m.player, p.Value)
+		if err != nil {
+			return nil, err
+		}
+		entry.Text = text
+	}
+
+	s.submitted[m.player] = struct{}{}
+	g.sendToAll(newMessage(relayProgress, relayProgressPayload{
+		Done:  len(s.submitted),
+		Total: len(s.entries),
+	}))
+
+	if s.finished(g) {
+		return s.next(g), nil
+	}
+	return nil, nil
+}
+
+// relayRevealState shows the chains one by one in the order they were
+// started.
+type relayRevealState struct {
+	chain int
+	timer *phaseTimer
+}
+
+func (s *relayRevealState) Enter(g *Game) {
+	g.logger.Info("Entering relay reveal", slog.Int("chain", s.chain))
+
+	g.sendToAll(newMessage(relayReveal, *g.relay.chains[s.chain]))
+
+	var next state = &endingState{}
+	if s.chain+1 < len(g.relay.chains) {
+		next = &relayRevealState{chain: s.chain + 1}
+	}
+	s.timer = newPhaseTimer(15*time.Second, func() {
+		g.expire(s, next)
+	})
+}
+
+func (s *relayRevealState) phaseTimer() *phaseTimer {
+	return s.timer
+}
+
+func (s *relayRevealState) Exit(g *Game) {
+	s.timer.Stop()
+	g.logger.Info("Exiting relay reveal", slog.Int("chain", s.chain))
+}
+
+func (s *relayRevealState) HandleMessage(g *Game, m *Message) state {
+	if state, ok := g.handleCommon(m); ok {
+		return state
+	}
+
+	switch m.Action {
+	default:
+		g.reject(m, errWrongState)
+		return nil
+	}
+}
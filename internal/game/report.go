@@ -0,0 +1,132 @@
+package game
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"cloud.google.com/go/firestore"
+	"github.com/google/uuid"
+)
+
+const (
+	reportChatSize    = 50
+	reportDrawingSize = 1000
+	maxLogLineSize    = 1 << 20
+)
+
+var ErrNotInGame = errors.New("player was not in the game")
+
+type LogEntry struct {
+	Time    time.Time `firestore:"time" json:"time"`
+	Player  string    `firestore:"player" json:"player"`
+	Action  int       `firestore:"action" json:"action"`
+	Payload string    `firestore:"payload" json:"payload"`
+}
+
+type Report struct {
+	ID       string `firestore:"-" json:"id"`
+	GameID   string `firestore:"game_id" json:"game_id"`
+	Reporter string `firestore:"reporter" json:"reporter"`
+	Reported string `firestore:"reported" json:"reported"`
+	// User ID of the reported player if they were logged in
+	ReportedUser string `firestore:"reported_user" json:"reported_user,omitempty"`
+	Reason       string `firestore:"reason" json:"reason"`
+	// Recent chat and guesses of every player
+	Chat []LogEntry `firestore:"chat" json:"chat"`
+	// Recent board actions of the reported player
+	Drawing    []LogEntry `firestore:"drawing" json:"drawing"`
+	Status     string     `firestore:"status" json:"status"`
+	Resolution string     `firestore:"resolution" json:"resolution,omitempty"`
+	ResolvedBy string     `firestore:"resolved_by" json:"resolved_by,omitempty"`
+	CreatedAt  time.Time  `firestore:"created_at" json:"created_at"`
+}
+
+// NewReport builds a report with context read from the game log, both
+// players must have joined the game.
+func NewReport(gameID, reporter, reported, reason string) (*Report, error) {
+	// Game IDs come from clients, only UUIDs may end up in the log path
+	if _, err := uuid.Parse(gameID); err != nil {
+		return nil, os.ErrNotExist
+	}
+
+	file, err := os.Open(fmt.Sprintf(gameLogPath, gameID))
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	r := &Report{
+		GameID:    gameID,
+		Reporter:  reporter,
+		Reported:  reported,
+		Reason:    reason,
+		Chat:      []LogEntry{},
+		Drawing:   []LogEntry{},
+		Status:    "open",
+		CreatedAt: time.Now(),
+	}
+
+	var reporterFound, reportedFound bool
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(nil, maxLogLineSize)
+	for scanner.Scan() {
+		var line struct {
+			LogEntry
+			Msg  string `json:"msg"`
+			User string `json:"user"`
+		}
+		if err := json.Unmarshal(scanner.Bytes(), &line); err != nil {
+			continue
+		}
+
+		switch line.Player {
+		case reporter:
+			reporterFound = true
+		case reported:
+			reportedFound = true
+			if line.User != "" {
+				r.ReportedUser = line.User
+			}
+		}
+		if line.Msg != receivedMessageLog {
+			continue
+		}
+
+		act := action(line.Action)
+		switch {
+		case act == chat || act == guess:
+			r.Chat = appendLimited(r.Chat, line.LogEntry, reportChatSize)
+		case isBoardAction(act) && line.Player == reported:
+			r.Drawing = appendLimited(r.Drawing, line.LogEntry, reportDrawingSize)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	if !reporterFound || !reportedFound {
+		return nil, ErrNotInGame
+	}
+	return r, nil
+}
+
+func StoreReport(ctx context.Context, db *firestore.Client, r *Report) error {
+	ref, _, err := db.Collection("reports").Add(ctx, r)
+	if err != nil {
+		return err
+	}
+	r.ID = ref.ID
+	return nil
+}
+
+func appendLimited(entries []LogEntry, e LogEntry, limit int) []LogEntry {
+	if len(entries) == limit {
+		entries = entries[1:]
+	}
+	return append(entries, e)
+}
@@ -0,0 +1,236 @@
+package game
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+
+	"cloud.google.com/go/firestore"
+	"github.com/alperenunal/draw2gather/internal/moderation"
+)
+
+var ErrGameClosed = errors.New("game is closed")
+
+// SettingsUpdate changes lobby settings, nil fields are left unchanged.
+type SettingsUpdate struct {
+	TargetScore *int    `json:"target_score,omitempty"`
+	MaxPlayers  *int    `json:"max_players,omitempty"`
+	Visibility  *bool   `json:"visibility,omitempty"`
+	Language    *string `json:"language,omitempty"`
+	// WordSet replaces the word sets with one set of the owner
+	WordSet     *string      `json:"word_set,omitempty"`
+	WordSets    []WordSetRef `json:"word_sets,omitempty"`
+	Mode        *string      `json:"mode,omitempty"`
+	Teams       *int         `json:"teams,omitempty"`
+	WordChoices *int         `json:"word_choices,omitempty"`
+
+	CustomWordMode  *string `json:"custom_word_mode,omitempty"`
+	OpenCustomWords *bool   `json:"open_custom_words,omitempty"`
+}
+
+func (g *Game) settings() SettingsUpdate {
+	mode := g.mode.name()
+	var wordSet *string
+	if len(g.wordSets) == 1 {
+		wordSet = &g.wordSets[0].Name
+	}
+	return SettingsUpdate{
+		TargetScore: &g.targetScore,
+		MaxPlayers:  &g.maxPlayers,
+		Visibility:  &g.visibility,
+		Language:    &g.language,
+		WordSet:     wordSet,
+		WordSets:    g.wordSets,
+		Mode:        &mode,
+		Teams:       &g.teams,
+		WordChoices: &g.wordChoices,
+
+		CustomWordMode:  &g.customWordMode,
+		OpenCustomWords: &g.openCustomWords,
+	}
+}
+
+// UpdateSettings changes the settings of a game in the lobby on behalf of
+// the owner, userID is used to load the owner's word sets.
+func (g *Game) UpdateSettings(playerID, userID string, u *SettingsUpdate) error {
+	errCh := make(chan error, 1)
+	g.post(func() state {
+		errCh <- g.updateSettings(playerID, userID, u)
+		return nil
+	})
+
+	select {
+	case err := <-errCh:
+		return err
+	case <-g.done:
+		return ErrGameClosed
+	}
+}
+
+func (g *Game) handleUpdateSettings(m *Message) error {
+	in, err := m.decodeMessage()
+	if err != nil {
+		return err
+	}
+	p := in.(payload[SettingsUpdate])
+
+	userID := g.sessions.GetString(m.player.ctx, "user_id")
+	return g.updateSettings(m.player.ID, userID, &p.Value)
+}
+
+func (g *Game) updateSettings(playerID, userID string, u *SettingsUpdate) error {
+	if playerID != g.owner {
+		return errNotOwner
+	}
+	if _, ok := g.state.(*waitingState); !ok {
+		return errWrongState
+	}
+
+	if u.TargetScore != nil && *u.TargetScore <= 0 {
+		return errInvalidSettings
+	}
+	if u.MaxPlayers != nil && (*u.MaxPlayers < 2 || *u.MaxPlayers < len(g.players)) {
+		return errInvalidSettings
+	}
+	if u.Teams != nil && !ValidTeams(*u.Teams) {
+		return errInvalidSettings
+	}
+	if u.WordChoices != nil && !ValidWordChoices(*u.WordChoices) {
+		return errInvalidSettings
+	}
+	if u.CustomWordMode != nil && !ValidCustomWordMode(*u.CustomWordMode) {
+		return errInvalidSettings
+	}
+	if u.Mode != nil && !ValidMode(*u.Mode) {
+		return errInvalidSettings
+	}
+	mode, teams := g.mode.name(), g.teams
+	if u.Mode != nil {
+		mode = *u.Mode
+	}
+	if u.Teams != nil {
+		teams = *u.Teams
+	}
+	if mode != ClassicMode && teams > 0 {
+		return errInvalidSettings
+	}
+
+	language, wordSets := g.language, g.wordSets
+	if u.Language != nil {
+		language = *u.Language
+	}
+	if u.WordSet != nil {
+		wordSets = []WordSetRef{{Name: *u.WordSet}}
+	}
+	if u.WordSets != nil {
+		wordSets = u.WordSets
+	}
+
+	// The lobby listing is updated first, the game only changes if the
+	// write succeeds
+	var (
+		updates      []firestore.Update
+		words        *Words
+		wordsChanged = language != g.language || u.WordSet != nil || u.WordSets != nil
+	)
+	if wordsChanged {
+		var err error
+		words, wordSets, err = LoadWordSets(context.Background(), g.db, language, wordSets, userID)
+		if err != nil {
+			return newGameError("word_set_unavailable", err.Error())
+		}
+
+		wordSet := ""
+		if len(wordSets) == 1 {
+			wordSet = wordSets[0].Name
+		}
+		updates = append(updates,
+			firestore.Update{Path: "language", Value: language},
+			firestore.Update{Path: "word_set", Value: wordSet},
+			firestore.Update{Path: "word_sets", Value: wordSets},
+		)
+	}
+	if u.TargetScore != nil {
+		updates = append(updates, firestore.Update{Path: "target_score", Value: *u.TargetScore})
+	}
+	if u.MaxPlayers != nil {
+		updates = append(updates, firestore.Update{Path: "max_players", Value: *u.MaxPlayers})
+	}
+	if u.Visibility != nil {
+		updates = append(updates, firestore.Update{Path: "visibility", Value: *u.Visibility})
+	}
+	if u.Mode != nil {
+		updates = append(updates, firestore.Update{Path: "mode", Value: *u.Mode})
+	}
+	if u.WordChoices != nil {
+		updates = append(updates, firestore.Update{Path: "word_choices", Value: *u.WordChoices})
+	}
+	customWordModeChanged := u.CustomWordMode != nil && *u.CustomWordMode != g.customWordMode
+	if customWordModeChanged {
+		updates = append(updates, firestore.Update{Path: "custom_word_mode", Value: *u.CustomWordMode})
+	}
+	if u.OpenCustomWords != nil {
+		updates = append(updates, firestore.Update{Path: "open_custom_words", Value: *u.OpenCustomWords})
+	}
+	teamsChanged := u.Teams != nil && *u.Teams != g.teams
+	if teamsChanged {
+		updates = append(updates, firestore.Update{Path: "teams", Value: *u.Teams})
+	}
+
+	if len(updates) == 0 {
+		return nil
+	}
+
+	_, err := g.db.Collection("games").Doc(g.id).Update(context.Background(), updates)
+	if err != nil {
+		return err
+	}
+
+	if wordsChanged {
+		g.setWords(words)
+		g.language = language
+		g.wordSets = wordSets
+		g.filter = moderation.Chat(language)
+		g.loadWordStats()
+	}
+	if u.TargetScore != nil {
+		g.targetScore = *u.TargetScore
+	}
+	if u.MaxPlayers != nil {
+		g.maxPlayers = *u.MaxPlayers
+	}
+	if u.Visibility != nil {
+		g.visibility = *u.Visibility
+	}
+	if u.Mode != nil {
+		g.mode = modes[*u.Mode]
+	}
+	if u.WordChoices != nil {
+		g.wordChoices = *u.WordChoices
+	}
+	if customWordModeChanged {
+		g.customWordMode = *u.CustomWordMode
+	}
+	if wordsChanged || customWordModeChanged {
+		g.resetWords()
+	}
+	if u.OpenCustomWords != nil {
+		g.openCustomWords = *u.OpenCustomWords
+	}
+	if teamsChanged {
+		g.teams = *u.Teams
+		g.teamScores = make([]int, g.teams)
+	}
+
+	g.logger.Info("Settings changed", slog.Int("target_score", g.targetScore),
+		slog.Int("max_players", g.maxPlayers), slog.Bool("visibility", g.visibility),
+		slog.String("language", g.language), slog.Any("word_sets", g.wordSets),
+		slog.String("mode", g.mode.name()), slog.Int("teams", g.teams),
+		slog.Int("word_choices", g.wordChoices), slog.String("custom_word_mode", g.customWordMode),
+		slog.Bool("open_custom_words", g.openCustomWords))
+	g.sendToAll(newMessage(settingsChanged, g.settings()))
+	if teamsChanged {
+		g.balanceTeams()
+	}
+	return nil
+}
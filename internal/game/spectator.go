@@ -0,0 +1,110 @@
+package game
+
+import (
+	"context"
+	"log/slog"
+
+	"cloud.google.com/go/firestore"
+)
+
+// Spectate makes the player join as a spectator, it must be called before
+// the player is registered.
+func (p *Player) Spectate() {
+	p.Spectator = true
+}
+
+func isSpectatorAction(act action) bool {
+	switch act {
+	case quit, chat, report, play:
+		return true
+	default:
+		return false
+	}
+}
+
+func (g *Game) handlePlay(m *Message) error {
+	if !m.player.Spectator {
+		return errInvalidAction
+	}
+
+	m.player.wantsToPlay = true
+	if _, ok := g.state.(*waitingState); ok {
+		return g.promoteSpectator(m.player)
+	}
+	return nil
+}
+
+// promoteSpectators moves spectators that asked to play into the game.
+func (g *Game) promoteSpectators() {
+	for _, p := range g.spectators {
+		if !p.wantsToPlay {
+			continue
+		}
+
+		err := g.promoteSpectator(p)
+		if err != nil {
+			g.reject(&Message{player: p, Action: play}, err)
+		}
+	}
+}
+
+func (g *Game) promoteSpectator(p *Player) error {
+	if g.maxPlayers > 0 && len(g.players) >= g.maxPlayers {
+		return errGameFull
+	}
+	for _, player := range g.players {
+		if player.Name == p.Name {
+			return errNameTaken
+		}
+	}
+
+	delete(g.spectators, p.ID)
+	p.Spectator = false
+	p.wantsToPlay = false
+	g.sessions.Remove(p.ctx, "spectator")
+	g.sessions.Commit(p.ctx)
+	g.db.Collection("games").Doc(g.id).Update(context.Background(), []firestore.Update{
+		{Path: "current_players", Value: firestore.ArrayUnion(p.Name)},
+		{Path: "spectators", Value: firestore.Increment(-1)},
+	})
+
+	g.addPlayer(p)
+	g.logger.Info("Spectator joined as player", slog.String("player", p.ID))
+
+	msg := newMessage(join, p)
+	g.sendToAll(msg)
+	return nil
+}
+
+func (g *Game) removeSpectator(p *Player) error {
+	if _, ok := g.spectators[p.ID]; !ok {
+		return errPlayerNotFound
+	}
+
+	g.sessions.Remove(p.ctx, "game_id")
+	g.sessions.Remove(p.ctx, "spectator")
+	g.sessions.Commit(p.ctx)
+
+	delete(g.spectators, p.ID)
+	g.disconnect(p)
+
+	g.db.Collection("games").Doc(g.id).Update(context.Background(), []firestore.Update{
+		{Path: "spectators", Value: firestore.Increment(-1)},
+	})
+
+	msg := newMessage(quit, p.ID)
+	g.sendToAll(msg)
+	return nil
+}
+
+// closeSpectators disconnects every spectator when the game closes.
+func (g *Game) closeSpectators() {
+	for _, p := range g.spectators {
+		g.sessions.Remove(p.ctx, "game_id")
+		g.sessions.Remove(p.ctx, "spectator")
+		g.sessions.Commit(p.ctx)
+
+		delete(g.spectators, p.ID)
+		g.disconnect(p)
+	}
+}
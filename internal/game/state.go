@@ -10,6 +10,44 @@ type state interface {
 	HandleMessage(*Game, *Message) state
 }
 
+// handleCommon handles actions allowed in every state, ok is false if the
+// action is left to the state.
+func (g *Game) handleCommon(m *Message) (state, bool) {
+	var (
+		next state
+		err  error
+	)
+	switch m.Action {
+	case quit:
+		next, err = g.handleQuit(m)
+	case kick:
+		next, err = g.handleKick(m)
+	case voteKick:
+		next, err = g.handleVoteKick(m)
+	case transferOwner:
+		err = g.handleTransferOwner(m)
+	case closeGame:
+		next, err = g.handleCloseGame(m)
+	case chat:
+		err = g.handleChat(m)
+	case report:
+		err = g.handleReport(m)
+	case play:
+		err = g.handlePlay(m)
+	case pause:
+		err = g.handlePause(m)
+	case resume:
+		err = g.handleResume(m)
+	default:
+		return nil, false
+	}
+
+	if err != nil {
+		g.reject(m, err)
+	}
+	return next, true
+}
+
 type waitingState struct {
 }
 
@@ -18,12 +56,17 @@ func (s *waitingState) Enter(g *Game) {
 
 	g.commands = []*Message{}
 	clear(g.answeredPlayers)
-	g.words = g.dictionary
 	g.currentPlayer = nil
+	g.drawers = nil
 	g.currentWord = ""
+	g.drawnWords = nil
+	g.relay = nil
 	for _, p := range g.players {
 		p.Score = 0
 	}
+	clear(g.teamScores)
+	g.lastTeam = 0
+	g.promoteSpectators()
 
 	g.sendToAll(newEmptyMessage(waiting))
 }
@@ -33,210 +76,246 @@ func (s *waitingState) Exit(g *Game) {
 }
 
 func (s *waitingState) HandleMessage(g *Game, m *Message) state {
+	if state, ok := g.handleCommon(m); ok {
+		return state
+	}
+
 	switch m.Action {
-	case quit:
-		state, err := g.handleQuit(m)
+	case start:
+		state, err := g.handleStart(m)
 		if err != nil {
-			g.logger.Error(err.Error())
+			g.reject(m, err)
 		}
 		return state
-	case kick:
-		state, err := g.handleKick(m)
+	case updateSettings:
+		err := g.handleUpdateSettings(m)
 		if err != nil {
-			g.logger.Error(err.Error())
+			g.reject(m, err)
 		}
-		return state
-	case chat:
-		err := g.handleChat(m)
+		return nil
+	case joinTeam:
+		err := g.handleJoinTeam(m)
 		if err != nil {
-			g.logger.Error(err.Error())
+			g.reject(m, err)
 		}
 		return nil
-	case start:
-		state, err := g.handleStart(m)
+	case addWords:
+		err := g.handleAddWords(m)
 		if err != nil {
-			g.logger.Error(err.Error())
+			g.reject(m, err)
 		}
-		return state
+		return nil
+	case removeWords:
+		err := g.handleRemoveWords(m)
+		if err != nil {
+			g.reject(m, err)
+		}
+		return nil
 	default:
+		g.reject(m, errWrongState)
 		return nil
 	}
 }
 
 type startingState struct {
-	timer *time.Timer
+	timer *phaseTimer
 }
 
 func (s *startingState) Enter(g *Game) {
 	g.logger.Info("Entering starting state")
 
+	// The first turn after waiting has no previous turn to check
+	if g.currentPlayer != nil {
+		g.updateIdle()
+	}
+	g.wordPicked = false
+
 	g.commands = []*Message{}
 	clear(g.answeredPlayers)
-	g.currentPlayer = g.pickPlayer()
+	clear(g.skipVotes)
+	g.choices = nil
+	g.rerolled = false
+	g.currentWord = ""
+	g.currentTier = ""
+	g.drawers = g.mode.pickDrawers(g)
+	if g.drawers == nil {
+		// Nobody can draw, the game goes back to the lobby right away
+		g.currentPlayer = nil
+		s.timer = newPhaseTimer(0, func() {
+			g.expire(s, &waitingState{})
+		})
+		return
+	}
+	g.currentPlayer = g.drawers[0]
+	g.turnStarted = time.Now()
 
 	msg := newMessage(starting, g.currentPlayer.ID)
 	g.sendToAll(msg)
+	if len(g.drawers) > 1 {
+		g.sendToAll(newMessage(coDrawers, g.drawerIDs()))
+	}
 
-	s.timer = time.AfterFunc(5*time.Second, func() {
-		g.stateCh <- &pickingState{}
+	s.timer = newPhaseTimer(5*time.Second, func() {
+		g.expire(s, &pickingState{})
 	})
 }
 
+func (s *startingState) phaseTimer() *phaseTimer {
+	return s.timer
+}
+
 func (s *startingState) Exit(g *Game) {
 	s.timer.Stop()
 	g.logger.Info("Exiting starting state")
 }
 
 func (s *startingState) HandleMessage(g *Game, m *Message) state {
-	switch m.Action {
-	case quit:
-		state, err := g.handleQuit(m)
-		if err != nil {
-			g.logger.Error(err.Error())
-		}
-		return state
-	case kick:
-		state, err := g.handleKick(m)
-		if err != nil {
-			g.logger.Error(err.Error())
-		}
+	if state, ok := g.handleCommon(m); ok {
 		return state
-	case chat:
-		err := g.handleChat(m)
-		if err != nil {
-			g.logger.Error(err.Error())
-		}
-		return nil
-	default:
-		return nil
 	}
+
+	g.reject(m, errWrongState)
+	return nil
 }
 
 type pickingState struct {
-	timer *time.Timer
+	timer *phaseTimer
 }
 
 func (s *pickingState) Enter(g *Game) {
 	g.logger.Info("Entering picking state")
 
-	words := g.pickWords()
+	g.choices = g.pickWords()
 
 	msg := newEmptyMessage(picking)
 	g.sendToAll(msg)
+	g.sendWordChoices()
 
-	msg = newMessage(pick, words)
-	g.sendToPlayer(g.currentPlayer, msg)
-
-	s.timer = time.AfterFunc(10*time.Second, func() {
-		g.stateCh <- &startingState{}
+	s.timer = newPhaseTimer(10*time.Second, func() {
+		g.expire(s, &startingState{})
 	})
 }
 
+func (s *pickingState) phaseTimer() *phaseTimer {
+	return s.timer
+}
+
 func (s *pickingState) Exit(g *Game) {
 	s.timer.Stop()
+	for _, c := range g.choices {
+		g.words.putBack(c.Word)
+	}
+	g.choices = nil
 	g.logger.Info("Exiting picking state")
 }
 
 func (s *pickingState) HandleMessage(g *Game, m *Message) state {
+	if state, ok := g.handleCommon(m); ok {
+		return state
+	}
+
 	switch m.Action {
-	case quit:
-		state, err := g.handleQuit(m)
+	case pick:
+		state, err := g.handlePick(m)
 		if err != nil {
-			g.logger.Error(err.Error())
+			g.reject(m, err)
 		}
 		return state
-	case kick:
-		state, err := g.handleKick(m)
+	case skip:
+		state, err := g.handleSkip(m)
 		if err != nil {
-			g.logger.Error(err.Error())
+			g.reject(m, err)
 		}
 		return state
-	case chat:
-		err := g.handleChat(m)
+	case reroll:
+		err := g.handleReroll(m)
 		if err != nil {
-			g.logger.Error(err.Error())
+			g.reject(m, err)
 		}
 		return nil
-	case pick:
-		state, err := g.handlePick(m)
-		if err != nil {
-			g.logger.Error(err.Error())
-		}
-		return state
+	default:
+		g.reject(m, errWrongState)
+		return nil
 	}
-	return nil
 }
 
 type drawingState struct {
-	timer *time.Timer
+	timer *phaseTimer
 }
 
 func (s *drawingState) Enter(g *Game) {
 	g.logger.Info("Entering drawing state")
+	g.wordPicked = true
 
 	msg := newEmptyMessage(drawing)
 	g.sendToAll(msg)
 
-	s.timer = time.AfterFunc(1*time.Minute, func() {
-		g.stateCh <- &startingState{}
+	s.timer = newPhaseTimer(1*time.Minute, func() {
+		g.expire(s, &startingState{})
 	})
 }
 
+func (s *drawingState) phaseTimer() *phaseTimer {
+	return s.timer
+}
+
 func (s *drawingState) Exit(g *Game) {
 	s.timer.Stop()
+	g.recordWord(g.currentWord, len(g.answeredPlayers) > 0)
 	g.logger.Info("Exiting drawing state")
 }
 
 func (s *drawingState) HandleMessage(g *Game, m *Message) state {
-	switch m.Action {
-	case quit:
-		state, err := g.handleQuit(m)
-		if err != nil {
-			g.logger.Error(err.Error())
-		}
+	if state, ok := g.handleCommon(m); ok {
 		return state
-	case kick:
-		state, err := g.handleKick(m)
-		if err != nil {
-			g.logger.Error(err.Error())
-		}
-		return state
-	case chat:
-		err := g.handleChat(m)
-		if err != nil {
-			g.logger.Error(err.Error())
-		}
-		return nil
+	}
+
+	switch m.Action {
 	case draw, erase, lineDraw, rectDraw, rectFill, circleDraw, circleFill,
-		changeColor, changePencilSize, changeEraserSize, clearBoard:
+		ellipseDraw, ellipseFill, polygonDraw, polygonFill, curveDraw,
+		changeColor, changeAlphaColor, changePencilSize, changeEraserSize, clearBoard:
 		err := g.handleBoardAction(m)
 		if err != nil {
-			g.logger.Error(err.Error())
+			g.reject(m, err)
 		}
 		return nil
 	case guess:
 		state, err := g.handleGuess(m)
 		if err != nil {
-			g.logger.Error(err.Error())
+			g.reject(m, err)
+		}
+		return state
+	case skip:
+		state, err := g.handleSkip(m)
+		if err != nil {
+			g.reject(m, err)
+		}
+		return state
+	case voteSkip:
+		state, err := g.handleVoteSkip(m)
+		if err != nil {
+			g.reject(m, err)
 		}
 		return state
 	default:
+		g.reject(m, errWrongState)
 		return nil
 	}
 }
 
 type endingState struct {
-	timer *time.Timer
+	timer *phaseTimer
 }
 
 func (s *endingState) Enter(g *Game) {
 	g.logger.Info("Entering ending state")
+	g.recordRecentWords()
 
 	msg := newEmptyMessage(ending)
 	g.sendToAll(msg)
 
-	s.timer = time.AfterFunc(15*time.Second, func() {
-		g.stateCh <- &waitingState{}
+	s.timer = newPhaseTimer(15*time.Second, func() {
+		g.expire(s, &waitingState{})
 	})
 }
 
@@ -246,28 +325,12 @@ func (s *endingState) Exit(g *Game) {
 }
 
 func (s *endingState) HandleMessage(g *Game, m *Message) state {
-	switch m.Action {
-	case quit:
-		state, err := g.handleQuit(m)
-		if err != nil {
-			g.logger.Error(err.Error())
-		}
+	if state, ok := g.handleCommon(m); ok {
 		return state
-	case kick:
-		state, err := g.handleKick(m)
-		if err != nil {
-			g.logger.Error(err.Error())
-		}
-		return state
-	case chat:
-		err := g.handleChat(m)
-		if err != nil {
-			g.logger.Error(err.Error())
-		}
-		return nil
-	default:
-		return nil
 	}
+
+	g.reject(m, errWrongState)
+	return nil
 }
 
 type closingState struct {
@@ -275,6 +338,7 @@ type closingState struct {
 
 func (s *closingState) Enter(g *Game) {
 	g.logger.Info("Entering closing state")
+	g.closeSpectators()
 	g.close()
 }
 
@@ -0,0 +1,112 @@
+package game
+
+import (
+	"log/slog"
+)
+
+const maxTeams = 4
+
+type teamPayload struct {
+	Player string `json:"player"`
+	Team   int    `json:"team"`
+}
+
+type teamScorePayload struct {
+	Team  int `json:"team"`
+	Score int `json:"score"`
+}
+
+// ValidTeams reports whether a game can be played with n teams, 0 plays
+// without teams.
+func ValidTeams(n int) bool {
+	return n == 0 || (n >= 2 && n <= maxTeams)
+}
+
+// smallestTeam returns the team with the fewest players, ties go to the
+// lower team number.
+func (g *Game) smallestTeam() int {
+	sizes := make([]int, g.teams+1)
+	for _, p := range g.players {
+		if p.Team > 0 {
+			sizes[p.Team]++
+		}
+	}
+
+	team := 1
+	for t := 2; t <= g.teams; t++ {
+		if sizes[t] < sizes[team] {
+			team = t
+		}
+	}
+	return team
+}
+
+// balanceTeams splits every player evenly across the teams in join order.
+func (g *Game) balanceTeams() {
+	for _, p := range g.playerQueue {
+		p.Team = 0
+	}
+	for _, p := range g.playerQueue {
+		if g.teams > 0 {
+			p.Team = g.smallestTeam()
+		}
+		g.sendToAll(newMessage(teamChanged, teamPayload{Player: p.ID, Team: p.Team}))
+	}
+}
+
+func (g *Game) handleJoinTeam(m *Message) error {
+	if g.teams == 0 {
+		return errTeamsDisabled
+	}
+
+	in, err := m.decodeMessage()
+	if err != nil {
+		return err
+	}
+	p := in.(payload[int])
+
+	if p.Value < 1 || p.Value > g.teams {
+		return errInvalidTeam
+	}
+
+	m.player.Team = p.Value
+	g.logger.Info("Player changed team", slog.String("player", m.player.ID), slog.Int("team", p.Value))
+	g.sendToAll(newMessage(teamChanged, teamPayload{Player: m.player.ID, Team: p.Value}))
+	return nil
+}
+
+// teamsReady reports whether every team has a drawer and someone to guess.
+func (g *Game) teamsReady() bool {
+	sizes := make([]int, g.teams+1)
+	for _, p := range g.players {
+		sizes[p.Team]++
+	}
+	for t := 1; t <= g.teams; t++ {
+		if sizes[t] < 2 {
+			return false
+		}
+	}
+	return true
+}
+
+// nextTeam returns the team whose turn it is, teams take turns in order.
+func (g *Game) nextTeam() int {
+	return g.lastTeam%g.teams + 1
+}
+
+func (g *Game) addScore(p *Player, points int) {
+	p.Score += points
+	g.sendToAll(newMessage(updateScore, scorePayload{
+		Player: p.ID,
+		Score:  p.Score,
+	}))
+
+	if g.teams == 0 {
+		return
+	}
+	g.teamScores[p.Team-1] += points
+	g.sendToAll(newMessage(updateTeamScore, teamScorePayload{
+		Team:  p.Team,
+		Score: g.teamScores[p.Team-1],
+	}))
+}
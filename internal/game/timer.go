@@ -0,0 +1,64 @@
+package game
+
+import "time"
+
+// phaseTimer is a timer for a state that can be paused.
+type phaseTimer struct {
+	timer     *time.Timer
+	fn        func()
+	deadline  time.Time
+	remaining time.Duration
+	paused    bool
+}
+
+func newPhaseTimer(d time.Duration, fn func()) *phaseTimer {
+	return &phaseTimer{
+		timer:    time.AfterFunc(d, fn),
+		fn:       fn,
+		deadline: time.Now().Add(d),
+	}
+}
+
+func (t *phaseTimer) Stop() {
+	t.timer.Stop()
+}
+
+func (t *phaseTimer) Remaining() time.Duration {
+	if t.paused {
+		return t.remaining
+	}
+	return max(0, time.Until(t.deadline))
+}
+
+func (t *phaseTimer) Pause() {
+	if t.paused {
+		return
+	}
+	t.timer.Stop()
+	t.remaining = t.Remaining()
+	t.paused = true
+}
+
+func (t *phaseTimer) Resume() {
+	if !t.paused {
+		return
+	}
+	t.paused = false
+	t.deadline = time.Now().Add(t.remaining)
+	t.timer = time.AfterFunc(t.remaining, t.fn)
+}
+
+// timeout moves the game on when the timer of a state runs out.
+type timeout struct {
+	from state
+	next state
+}
+
+// expire is called by the timer of from, the game only moves on to next if
+// it is still in from.
+func (g *Game) expire(from, next state) {
+	select {
+	case g.stateCh <- timeout{from: from, next: next}:
+	case <-g.done:
+	}
+}
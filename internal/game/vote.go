@@ -0,0 +1,180 @@
+package game
+
+import (
+	"log/slog"
+	"math"
+	"time"
+)
+
+const (
+	voteDuration = 30 * time.Second
+	voteCooldown = 60 * time.Second
+	// Votes cannot be started right after a turn starts so a drawer is not
+	// kicked before they had a chance to draw
+	voteGracePeriod = 10 * time.Second
+	minVotePlayers  = 3
+
+	DefaultSkipThreshold = 0.5
+)
+
+type kickVote struct {
+	target *Player
+	voters map[*Player]struct{}
+	timer  *time.Timer
+}
+
+// votes counts the voters that are still in the game.
+func (v *kickVote) votes(g *Game) int {
+	n := 0
+	for p := range v.voters {
+		if _, ok := g.players[p.ID]; ok {
+			n++
+		}
+	}
+	return n
+}
+
+// required is a majority of the players other than the target.
+func (v *kickVote) required(g *Game) int {
+	return (len(g.players)-1)/2 + 1
+}
+
+func (g *Game) handleVoteKick(m *Message) (state, error) {
+	in, err := m.decodeMessage()
+	if err != nil {
+		return nil, err
+	}
+	p := in.(payload[string])
+
+	target, ok := g.players[p.Value]
+	if !ok {
+		return nil, errPlayerNotFound
+	}
+	if target == m.player {
+		return nil, errInvalidTarget
+	}
+
+	if g.kickVote == nil {
+		if len(g.players) < minVotePlayers {
+			return nil, errNotEnoughPlayers
+		}
+		if time.Now().Before(g.voteCooldownEnd) {
+			return nil, errVoteCooldown
+		}
+		if time.Since(g.turnStarted) < voteGracePeriod {
+			return nil, errVoteCooldown
+		}
+
+		vote := &kickVote{
+			target: target,
+			voters: make(map[*Player]struct{}),
+		}
+		vote.timer = time.AfterFunc(voteDuration, func() {
+			g.post(func() state {
+				if g.kickVote == vote {
+					g.endKickVote(false)
+				}
+				return nil
+			})
+		})
+		g.kickVote = vote
+		g.logger.Info("Kick vote started", slog.String("player", m.player.ID),
+			slog.String("target", target.ID))
+	} else if g.kickVote.target != target {
+		return nil, errVoteInProgress
+	}
+
+	vote := g.kickVote
+	vote.voters[m.player] = struct{}{}
+	if vote.votes(g) < vote.required(g) {
+		g.sendVoteKick(false, false)
+		return nil, nil
+	}
+
+	g.endKickVote(true)
+	g.banPlayer(target)
+	g.sendToPlayer(target, newEmptyMessage(kick))
+	return g.removePlayer(target)
+}
+
+func (g *Game) endKickVote(passed bool) {
+	g.kickVote.timer.Stop()
+	g.sendVoteKick(true, passed)
+	g.kickVote = nil
+	g.voteCooldownEnd = time.Now().Add(voteCooldown)
+}
+
+func (g *Game) sendVoteKick(ended, passed bool) {
+	msg := newMessage(voteKickStatus, votePayload{
+		Target:   g.kickVote.target.ID,
+		Votes:    g.kickVote.votes(g),
+		Required: g.kickVote.required(g),
+		Ended:    ended,
+		Passed:   passed,
+	})
+	g.sendToAll(msg)
+}
+
+// skipTurn ends the turn without changing scores and reveals the word if
+// one was picked.
+func (g *Game) skipTurn() state {
+	g.logger.Info("Turn skipped", slog.String("player", g.currentPlayer.ID),
+		slog.String("word", g.currentWord))
+
+	msg := newMessage(skipped, skipPayload{
+		Player: g.currentPlayer.ID,
+		Word:   g.currentWord,
+	})
+	g.sendToAll(msg)
+	return &startingState{}
+}
+
+func (g *Game) handleSkip(m *Message) (state, error) {
+	if !g.isDrawer(m.player) {
+		return nil, errNotYourTurn
+	}
+	if g.paused() {
+		return nil, errPaused
+	}
+	m.player.active = true
+	return g.skipTurn(), nil
+}
+
+func (g *Game) requiredSkipVotes() int {
+	guessers := len(g.players) - len(g.drawers)
+	return max(1, int(math.Ceil(g.skipThreshold*float64(guessers))))
+}
+
+func (g *Game) handleVoteSkip(m *Message) (state, error) {
+	if g.isDrawer(m.player) {
+		return nil, errCannotVote
+	}
+	if g.paused() {
+		return nil, errPaused
+	}
+
+	g.skipVotes[m.player] = struct{}{}
+	m.player.active = true
+	votes := 0
+	for p := range g.skipVotes {
+		if _, ok := g.players[p.ID]; ok {
+			votes++
+		}
+	}
+
+	required := g.requiredSkipVotes()
+	passed := votes >= required
+	msg := newMessage(voteSkipStatus, votePayload{
+		Target:   g.currentPlayer.ID,
+		Votes:    votes,
+		Required: required,
+		Ended:    passed,
+		Passed:   passed,
+	})
+	g.sendToAll(msg)
+
+	if passed {
+		return g.skipTurn(), nil
+	}
+	return nil, nil
+}
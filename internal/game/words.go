@@ -0,0 +1,122 @@
+package game
+
+import (
+	"context"
+	"errors"
+
+	"cloud.google.com/go/firestore"
+)
+
+const (
+	MaxWordSets = 10
+	maxWeight   = 10
+)
+
+var (
+	ErrWordSetNotFound  = errors.New("word set not found")
+	ErrLanguageMismatch = errors.New("word set language does not match game language")
+	ErrLoginRequired    = errors.New("user_id is required")
+	ErrInvalidWordSets  = errors.New("invalid word sets")
+)
+
+type wordSet struct {
+	Language string   `firestore:"language"`
+	Words    []string `firestore:"words"`
+	Public   bool     `firestore:"public"`
+}
+
+// WordSetRef points to a word set a game draws words from. Sets of other
+// users can only be used if they are public.
+type WordSetRef struct {
+	// User that owns the set, empty for the default set and the caller's
+	// own sets
+	Owner string `firestore:"owner" json:"owner,omitempty"`
+	Name  string `firestore:"name" json:"name"`
+	// Sets with a higher weight come up earlier, defaults to 1
+	Weight float64 `firestore:"weight" json:"weight"`
+}
+
+// loadWordSet loads the default word set of a language or a word set of a
+// user, the set must be in the given language.
+func loadWordSet(ctx context.Context, db *firestore.Client, language string, ref WordSetRef, userID string) ([]string, error) {
+	var (
+		doc *firestore.DocumentSnapshot
+		err error
+	)
+
+	owner := ref.Owner
+	switch {
+	case ref.Name == "default" && owner == "":
+		doc, err = db.Collection("word_sets").Doc(language).Get(ctx)
+	case owner == "" && userID == "":
+		return nil, ErrLoginRequired
+	default:
+		if owner == "" {
+			owner = userID
+		}
+		doc, err = db.Collection("players").Doc(owner).Collection("word_sets").Doc(ref.Name).Get(ctx)
+	}
+	if err != nil {
+		// Get returns the snapshot of a missing document along with its error
+		if doc != nil && !doc.Exists() {
+			return nil, ErrWordSetNotFound
+		}
+		return nil, err
+	}
+
+	var ws wordSet
+	err = doc.DataTo(&ws)
+	if err != nil {
+		return nil, err
+	}
+	// Private sets of other users look like missing sets
+	if owner != "" && owner != userID && !ws.Public {
+		return nil, ErrWordSetNotFound
+	}
+	if ws.Language != language {
+		return nil, ErrLanguageMismatch
+	}
+	return ws.Words, nil
+}
+
+// Words are the merged words of a game's word sets.
+type Words struct {
+	// Weights of the words, a word in several sets keeps its highest weight
+	Weights map[string]float64
+	// Words of the default set, only their stats are shared between games
+	Default []string
+}
+
+// LoadWordSets loads and merges word sets. The refs are returned with
+// missing weights set to 1.
+func LoadWordSets(ctx context.Context, db *firestore.Client, language string, refs []WordSetRef, userID string) (*Words, []WordSetRef, error) {
+	if len(refs) == 0 || len(refs) > MaxWordSets {
+		return nil, nil, ErrInvalidWordSets
+	}
+
+	words := &Words{Weights: make(map[string]float64)}
+	resolved := make([]WordSetRef, 0, len(refs))
+	for _, ref := range refs {
+		if ref.Weight == 0 {
+			ref.Weight = 1
+		}
+		if ref.Weight < 0 || ref.Weight > maxWeight {
+			return nil, nil, ErrInvalidWordSets
+		}
+
+		list, err := loadWordSet(ctx, db, language, ref, userID)
+		if err != nil {
+			return nil, nil, err
+		}
+		// Refs are shown to every player, own sets keep an empty owner so
+		// the account ID stays hidden
+		if ref.Owner == "" && ref.Name == "default" {
+			words.Default = list
+		}
+		resolved = append(resolved, ref)
+		for _, word := range list {
+			words.Weights[word] = max(words.Weights[word], ref.Weight)
+		}
+	}
+	return words, resolved, nil
+}
@@ -0,0 +1,174 @@
+package moderation
+
+import (
+	"bufio"
+	"bytes"
+	"embed"
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+//go:embed words/*.txt words/review/*.txt
+var wordFiles embed.FS
+
+var Languages = []string{"TR", "EN", "DE"}
+
+// Words returns the banned words of the given languages.
+func Words(langs ...string) []string {
+	return readWords("words", langs)
+}
+
+// ReviewWords returns words of the given languages that are offensive in
+// some contexts but also ordinary words, they are flagged instead of banned.
+func ReviewWords(langs ...string) []string {
+	return readWords("words/review", langs)
+}
+
+func readWords(dir string, langs []string) []string {
+	var words []string
+	for _, lang := range langs {
+		data, err := wordFiles.ReadFile(fmt.Sprintf("%s/%s.txt", dir, strings.ToLower(lang)))
+		if err != nil {
+			continue
+		}
+
+		scanner := bufio.NewScanner(bytes.NewReader(data))
+		for scanner.Scan() {
+			if word := strings.TrimSpace(scanner.Text()); word != "" {
+				words = append(words, strings.ToLower(word))
+			}
+		}
+	}
+	return words
+}
+
+type wordListFilter struct {
+	words map[string]struct{}
+}
+
+// WordList matches whole words case-insensitively and masks them with '*'.
+func WordList(words []string) Filter {
+	f := &wordListFilter{words: make(map[string]struct{}, len(words))}
+	for _, word := range words {
+		f.words[strings.ToLower(word)] = struct{}{}
+	}
+	return f
+}
+
+func (f *wordListFilter) Name() string {
+	return "word_list"
+}
+
+func (f *wordListFilter) Apply(text string) (string, bool) {
+	var (
+		b       strings.Builder
+		matched bool
+	)
+
+	isWord := func(r rune) bool { return unicode.IsLetter(r) || unicode.IsDigit(r) }
+	for len(text) > 0 {
+		end := strings.IndexFunc(text, func(r rune) bool { return !isWord(r) })
+		if end == 0 {
+			_, size := utf8.DecodeRuneInString(text)
+			b.WriteString(text[:size])
+			text = text[size:]
+			continue
+		}
+		if end < 0 {
+			end = len(text)
+		}
+
+		word := text[:end]
+		if _, ok := f.words[strings.ToLower(word)]; ok {
+			matched = true
+			b.WriteString(strings.Repeat("*", utf8.RuneCountInString(word)))
+		} else {
+			b.WriteString(word)
+		}
+		text = text[end:]
+	}
+
+	return b.String(), matched
+}
+
+type maxLengthFilter struct {
+	max int
+}
+
+// MaxLength matches texts longer than max runes and truncates them.
+func MaxLength(max int) Filter {
+	return &maxLengthFilter{max: max}
+}
+
+func (f *maxLengthFilter) Name() string {
+	return "max_length"
+}
+
+func (f *maxLengthFilter) Apply(text string) (string, bool) {
+	if utf8.RuneCountInString(text) <= f.max {
+		return text, false
+	}
+	return string([]rune(text)[:f.max]), true
+}
+
+type squashFilter struct {
+	max int
+}
+
+// SquashRepeats matches runs of the same character longer than max and
+// shortens them to max.
+func SquashRepeats(max int) Filter {
+	return &squashFilter{max: max}
+}
+
+func (f *squashFilter) Name() string {
+	return "repeated_characters"
+}
+
+func (f *squashFilter) Apply(text string) (string, bool) {
+	var (
+		b       strings.Builder
+		prev    rune
+		run     int
+		matched bool
+	)
+	for _, r := range text {
+		if r == prev {
+			run++
+		} else {
+			prev = r
+			run = 1
+		}
+
+		if run > f.max {
+			matched = true
+			continue
+		}
+		b.WriteRune(r)
+	}
+	return b.String(), matched
+}
+
+var linkRegexp = regexp.MustCompile(`(?i)\b(?:https?://|www\.)\S+|\b[a-z0-9-]+\.(?:com|net|org|io|gg|me|ly|tv|xyz|de|tr)\b\S*`)
+
+type linkFilter struct{}
+
+// Links matches URLs and strips them from the text.
+func Links() Filter {
+	return &linkFilter{}
+}
+
+func (f *linkFilter) Name() string {
+	return "link"
+}
+
+func (f *linkFilter) Apply(text string) (string, bool) {
+	if !linkRegexp.MatchString(text) {
+		return text, false
+	}
+	out := linkRegexp.ReplaceAllString(text, "")
+	return strings.Join(strings.Fields(out), " "), true
+}
@@ -0,0 +1,106 @@
+package moderation
+
+// Verdict is the outcome of a pipeline, verdicts are ordered by severity.
+type Verdict int
+
+const (
+	Allow Verdict = iota
+	Flag
+	Mask
+	Reject
+)
+
+// Action is what a pipeline does when a filter matches.
+type Action int
+
+const (
+	// FlagAction keeps the text unchanged and records the filter name
+	FlagAction Action = iota
+	// MaskAction replaces the text with the filter's output
+	MaskAction
+	// RejectAction stops the pipeline and rejects the text
+	RejectAction
+)
+
+type Filter interface {
+	Name() string
+	// Apply returns the text with offending parts masked or removed and
+	// whether anything matched.
+	Apply(text string) (string, bool)
+}
+
+type Result struct {
+	Text    string
+	Verdict Verdict
+	Flags   []string
+}
+
+type step struct {
+	filter Filter
+	action Action
+}
+
+type Pipeline struct {
+	steps []step
+}
+
+func New() *Pipeline {
+	return &Pipeline{}
+}
+
+func (p *Pipeline) Use(f Filter, act Action) *Pipeline {
+	p.steps = append(p.steps, step{filter: f, action: act})
+	return p
+}
+
+func (p *Pipeline) Check(text string) Result {
+	res := Result{Text: text, Verdict: Allow}
+	for _, s := range p.steps {
+		out, matched := s.filter.Apply(res.Text)
+		if !matched {
+			continue
+		}
+		res.Flags = append(res.Flags, s.filter.Name())
+
+		switch s.action {
+		case RejectAction:
+			res.Verdict = Reject
+			return res
+		case MaskAction:
+			res.Text = out
+			res.Verdict = max(res.Verdict, Mask)
+		case FlagAction:
+			res.Verdict = max(res.Verdict, Flag)
+		}
+	}
+	return res
+}
+
+// Chat is applied to chat messages and guesses.
+func Chat(lang string) *Pipeline {
+	return New().
+		Use(MaxLength(200), MaskAction).
+		Use(SquashRepeats(3), MaskAction).
+		Use(Links(), MaskAction).
+		Use(WordList(Words(lang)), MaskAction).
+		Use(WordList(ReviewWords(lang)), FlagAction)
+}
+
+// Name is applied to player names, names are checked against every language.
+func Name() *Pipeline {
+	return New().
+		Use(MaxLength(20), RejectAction).
+		Use(Links(), RejectAction).
+		Use(WordList(Words(Languages...)), RejectAction).
+		Use(WordList(ReviewWords(Languages...)), FlagAction).
+		Use(SquashRepeats(3), FlagAction)
+}
+
+// Word is applied to each word of a custom word set.
+func Word(lang string) *Pipeline {
+	return New().
+		Use(MaxLength(32), RejectAction).
+		Use(Links(), RejectAction).
+		Use(WordList(Words(lang)), RejectAction).
+		Use(WordList(ReviewWords(lang)), FlagAction)
+}
@@ -0,0 +1,110 @@
+package moderation
+
+import (
+	"context"
+	"time"
+
+	"cloud.google.com/go/firestore"
+)
+
+type SanctionType string
+
+const (
+	// Mute blocks chat and shown guesses
+	Mute SanctionType = "mute"
+	// NoCreate blocks creating games
+	NoCreate SanctionType = "no_create"
+	// Ban blocks everything
+	Ban SanctionType = "ban"
+)
+
+type SanctionScope string
+
+const (
+	// AccountScope sanctions a logged in user by user_id
+	AccountScope SanctionScope = "account"
+	// SessionScope sanctions a browser session by player_id
+	SessionScope SanctionScope = "session"
+)
+
+type Sanction struct {
+	ID        string        `firestore:"-" json:"id"`
+	Scope     SanctionScope `firestore:"scope" json:"scope"`
+	Subject   string        `firestore:"subject" json:"subject"`
+	Type      SanctionType  `firestore:"type" json:"type"`
+	Reason    string        `firestore:"reason" json:"reason"`
+	ReportID  string        `firestore:"report_id" json:"report_id,omitempty"`
+	CreatedAt time.Time     `firestore:"created_at" json:"created_at"`
+	// Zero means the sanction never expires
+	ExpiresAt time.Time `firestore:"expires_at" json:"expires_at"`
+}
+
+func (s *Sanction) Active(now time.Time) bool {
+	return s.ExpiresAt.IsZero() || now.Before(s.ExpiresAt)
+}
+
+// Restricts reports whether the sanction blocks what t blocks.
+func (s *Sanction) Restricts(t SanctionType) bool {
+	return s.Type == t || s.Type == Ban
+}
+
+// Matches reports whether the sanction applies to the given player.
+func (s *Sanction) Matches(playerID, userID string) bool {
+	switch s.Scope {
+	case SessionScope:
+		return s.Subject == playerID
+	case AccountScope:
+		return userID != "" && s.Subject == userID
+	default:
+		return false
+	}
+}
+
+// ActiveSanctions returns unexpired sanctions of a session and, if logged
+// in, of its account.
+func ActiveSanctions(ctx context.Context, db *firestore.Client, playerID, userID string) ([]*Sanction, error) {
+	subjects := map[SanctionScope]string{SessionScope: playerID}
+	if userID != "" {
+		subjects[AccountScope] = userID
+	}
+
+	now := time.Now()
+	sanctions := []*Sanction{}
+	for scope, subject := range subjects {
+		if subject == "" {
+			continue
+		}
+
+		docs, err := db.Collection("sanctions").
+			Where("scope", "==", scope).
+			Where("subject", "==", subject).
+			Documents(ctx).
+			GetAll()
+		if err != nil {
+			return nil, err
+		}
+
+		for _, doc := range docs {
+			var s Sanction
+			err := doc.DataTo(&s)
+			if err != nil {
+				return nil, err
+			}
+			s.ID = doc.Ref.ID
+			if s.Active(now) {
+				sanctions = append(sanctions, &s)
+			}
+		}
+	}
+	return sanctions, nil
+}
+
+// Find returns the first sanction that restricts t, or nil.
+func Find(sanctions []*Sanction, t SanctionType) *Sanction {
+	for _, s := range sanctions {
+		if s.Restricts(t) {
+			return s
+		}
+	}
+	return nil
+}